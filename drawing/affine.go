@@ -0,0 +1,98 @@
+package drawing
+
+import "math"
+
+// Affine is a 2x3 affine transform matrix, mapping a point p to
+// x' = m[0][0]*p.X + m[0][1]*p.Y + m[0][2]
+// y' = m[1][0]*p.X + m[1][1]*p.Y + m[1][2]
+type Affine [2][3]float64
+
+// Identity is the transform that leaves points unchanged
+var Identity = Affine{{1, 0, 0}, {0, 1, 0}}
+
+// Apply returns p transformed by m
+func (m Affine) Apply(p FPoint) FPoint {
+	return FPoint{
+		X: m[0][0]*p.X + m[0][1]*p.Y + m[0][2],
+		Y: m[1][0]*p.X + m[1][1]*p.Y + m[1][2],
+	}
+}
+
+// Mul returns the transform that applies n then m
+func (m Affine) Mul(n Affine) Affine {
+	return Affine{
+		{m[0][0]*n[0][0] + m[0][1]*n[1][0], m[0][0]*n[0][1] + m[0][1]*n[1][1], m[0][0]*n[0][2] + m[0][1]*n[1][2] + m[0][2]},
+		{m[1][0]*n[0][0] + m[1][1]*n[1][0], m[1][0]*n[0][1] + m[1][1]*n[1][1], m[1][0]*n[0][2] + m[1][1]*n[1][2] + m[1][2]},
+	}
+}
+
+// Invert returns the inverse transform, ok is false if m is singular
+func (m Affine) Invert() (inv Affine, ok bool) {
+	det := m[0][0]*m[1][1] - m[0][1]*m[1][0]
+	if det == 0 {
+		return Affine{}, false
+	}
+	inv[0][0] = m[1][1] / det
+	inv[0][1] = -m[0][1] / det
+	inv[1][0] = -m[1][0] / det
+	inv[1][1] = m[0][0] / det
+	inv[0][2] = -(inv[0][0]*m[0][2] + inv[0][1]*m[1][2])
+	inv[1][2] = -(inv[1][0]*m[0][2] + inv[1][1]*m[1][2])
+	return inv, true
+}
+
+// Transform applies m to all points in the drawing
+func (drawing *Drawing) Transform(m Affine) {
+	for i := range drawing.Paths {
+		for j, p := range drawing.Paths[i].Points {
+			drawing.Paths[i].Points[j] = m.Apply(p)
+		}
+	}
+}
+
+// ScaleT is Scale returning the transform applied, accumulate with Mul to keep a model to render matrix
+func (drawing *Drawing) ScaleT(scalar float64) Affine {
+	drawing.Scale(scalar)
+	return Affine{{scalar, 0, 0}, {0, scalar, 0}}
+}
+
+// TranslateT is Translate returning the transform applied
+func (drawing *Drawing) TranslateT(delta FPoint) Affine {
+	drawing.Translate(delta)
+	return Affine{{1, 0, delta.X}, {0, 1, delta.Y}}
+}
+
+// RotateT is Rotate returning the transform applied
+func (drawing *Drawing) RotateT(angle float64) Affine {
+	drawing.Rotate(angle)
+	cos := math.Cos(ToRadians(angle))
+	sin := math.Sin(ToRadians(angle))
+	return Affine{{cos, -sin, 0}, {sin, cos, 0}}
+}
+
+// IsometricMatrix lays the drawing's plane on the floor of a standard isometric view, the x and y axes drawn 30 degrees
+// either side of horizontal with no foreshortening along them:
+//
+//	x' = (x - y) * cos 30 = 0.866*x - 0.866*y
+//	y' = (x + y) * sin 30 = 0.5*x + 0.5*y
+var IsometricMatrix = Affine{{math.Sqrt(3) / 2, -math.Sqrt(3) / 2, 0}, {0.5, 0.5, 0}}
+
+// Isometric applies IsometricMatrix for a pseudo 3D look, use StackDepth after it to give the flat drawing thickness
+func (drawing *Drawing) Isometric() {
+	drawing.Transform(IsometricMatrix)
+}
+
+// StackDepth fakes depth by adding layers-1 copies of the paths below the drawing, copy i offset by i*step and drawn before it, farthest first, so the original stays on top.
+// With y up, a step of {0, -1} after Isometric extrudes the drawing downward like a slab. layers below 2 changes nothing
+func (drawing *Drawing) StackDepth(layers int, step FPoint) {
+	if layers < 2 {
+		return
+	}
+	paths := make([]Path, 0, layers*len(drawing.Paths))
+	for i := layers - 1; i >= 1; i-- {
+		layer := drawing.Clone()
+		layer.Translate(FPoint{X: step.X * float64(i), Y: step.Y * float64(i)})
+		paths = append(paths, layer.Paths...)
+	}
+	drawing.Paths = append(paths, drawing.Paths...)
+}
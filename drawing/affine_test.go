@@ -0,0 +1,61 @@
+package drawing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTransformReturningVariants(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 1, Y: 2}, ColorBLACK)
+	drw.LineTo(FPoint{X: -3, Y: 0.5})
+	orig := drw.Clone()
+	m := Identity
+	m = drw.ScaleT(2).Mul(m)
+	m = drw.RotateT(30).Mul(m)
+	m = drw.TranslateT(FPoint{X: 5, Y: -1}).Mul(m)
+	inv, ok := m.Invert()
+	if !ok {
+		t.Fatal("transform not invertible")
+	}
+	for i, p := range orig.Paths[0].Points {
+		got := drw.Paths[0].Points[i]
+		want := m.Apply(p)
+		if math.Abs(got.X-want.X) > 1e-9 || math.Abs(got.Y-want.Y) > 1e-9 {
+			t.Errorf("point %d: drawing has %v, accumulated matrix gives %v", i, got, want)
+		}
+		back := inv.Apply(got)
+		if math.Abs(back.X-p.X) > 1e-9 || math.Abs(back.Y-p.Y) > 1e-9 {
+			t.Errorf("point %d: inverse gives %v, want %v", i, back, p)
+		}
+	}
+}
+
+func TestIsometricStackDepth(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorBLACK)
+	drw.LineTo(FPoint{X: 1, Y: 0})
+	drw.LineTo(FPoint{X: 1, Y: 1})
+	drw.Isometric()
+	want := []FPoint{{X: 0, Y: 0}, {X: math.Sqrt(3) / 2, Y: 0.5}, {X: 0, Y: 1}}
+	for i, w := range want {
+		if p := drw.Paths[0].Points[i]; math.Abs(p.X-w.X) > 1e-9 || math.Abs(p.Y-w.Y) > 1e-9 {
+			t.Errorf("isometric point %d = %v, want %v", i, p, w)
+		}
+	}
+	top := drw.Clone()
+	drw.StackDepth(3, FPoint{X: 0, Y: -1})
+	if len(drw.Paths) != 3 {
+		t.Fatalf("%d paths, want 3", len(drw.Paths))
+	}
+	if !(&Drawing{Paths: drw.Paths[2:]}).Equal(top, 0) {
+		t.Error("the original is not the last, top, layer")
+	}
+	if p := drw.Paths[0].Points[2]; math.Abs(p.Y-(1-2)) > 1e-9 {
+		t.Errorf("farthest layer point %v, want y -1", p)
+	}
+	drw.StackDepth(1, FPoint{X: 5})
+	if len(drw.Paths) != 3 {
+		t.Error("StackDepth(1) changed the drawing")
+	}
+}
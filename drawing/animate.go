@@ -0,0 +1,252 @@
+package drawing
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"math"
+)
+
+// FrameFunc receives each frame of an animation in order from frame 0, to encode, stream or save it.
+// img is reused for the next frame, so copy it to keep it. Returning an error stops the animation with that error
+type FrameFunc func(frame int, img *image.RGBA) error
+
+var errNoFrames = errors.New("animation needs at least 1 frame")
+
+// AnimateGrowth calls fn with frames images of size rect that draw the paths in order, frame i showing the first (i+1)/frames of them,
+// so the last frame is the whole drawing. It is framed like RenderPng, flipped and centered once so the growing drawing does not move. The drawing is unchanged
+func (drawing *Drawing) AnimateGrowth(rect image.Rectangle, frames int, fn FrameFunc) error {
+	if frames < 1 {
+		return errNoFrames
+	}
+	drw := drawing.Clone()
+	drw.Flip(true)
+	drw.CenterWithMargin(RectBounds(rect), FPoint{X: 0.1, Y: 0.1}) //add a 10% of size margin
+	img := image.NewRGBA(rect)
+	start := 0
+	for i := 0; i < frames; i++ {
+		end := len(drw.Paths) * (i + 1) / frames
+		drw.DrawRangeToImage(img, start, end) // earlier paths are already drawn
+		start = end
+		if err := fn(i, img); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AnimateExplode calls fn with frames images of size rect of Explode stepping t evenly from 0 at frame 0 to maxT at the last frame.
+// Every frame is framed like the last, the most spread out for a positive maxT, so the paths move and the view does not. The drawing is unchanged
+func (drawing *Drawing) AnimateExplode(rect image.Rectangle, frames int, maxT float64, center FPoint, fn FrameFunc) error {
+	if frames < 1 {
+		return errNoFrames
+	}
+	drw := drawing.Clone()
+	db := drw.Bounds()
+	drw.Flip(true)
+	center.Y = db.Max.Y - center.Y + db.Min.Y // flip center with the drawing
+	img := image.NewRGBA(rect)
+	scale, delta := drw.Explode(maxT, center).CenterTransform(RectBounds(rect), FPoint{X: 0.1, Y: 0.1})
+	for i := 0; i < frames; i++ {
+		t := maxT
+		if frames > 1 {
+			t = maxT * float64(i) / float64(frames-1)
+		}
+		ex := drw.Explode(t, center)
+		ex.Scale(scale)
+		ex.Translate(delta)
+		draw.Draw(img, img.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		ex.DrawToImage(img)
+		if err := fn(i, img); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Morph returns the drawing t of the way to to, 0 the drawing and 1 to, pairing paths by index.
+// Each pair is resampled to the same point count along its length, closed paths ending back at their start, and points and colors move in a straight line.
+// A path with no partner shrinks into, or grows out of, the mean of its points. Neither drawing is changed
+func (drawing *Drawing) Morph(to *Drawing, t float64) *Drawing {
+	n := len(drawing.Paths)
+	if len(to.Paths) > n {
+		n = len(to.Paths)
+	}
+	out := &Drawing{Paths: make([]Path, 0, n)}
+	for i := 0; i < n; i++ {
+		var a, b []FPoint
+		var ca, cb color.RGBA
+		if i < len(drawing.Paths) {
+			a, ca = outline(&drawing.Paths[i]), drawing.Paths[i].Color
+		}
+		if i < len(to.Paths) {
+			b, cb = outline(&to.Paths[i]), to.Paths[i].Color
+		}
+		if len(a) == 0 && len(b) == 0 {
+			continue
+		}
+		switch {
+		case len(a) == 0:
+			a, ca = collapse(b), cb
+		case len(b) == 0:
+			b, cb = collapse(a), ca
+		}
+		count := len(a)
+		if len(b) > count {
+			count = len(b)
+		}
+		a, b = resample(a, count), resample(b, count)
+		pa := Path{Points: make([]FPoint, count), Color: lerpColor(ca, cb, t)}
+		for j := range pa.Points {
+			pa.Points[j] = FPoint{X: a[j].X + (b[j].X-a[j].X)*t, Y: a[j].Y + (b[j].Y-a[j].Y)*t}
+		}
+		out.Paths = append(out.Paths, pa)
+	}
+	return out
+}
+
+// outline returns the points of pa, ending with its first point again if closed
+func outline(pa *Path) []FPoint {
+	if pa.Closed && len(pa.Points) > 1 {
+		return append(append([]FPoint(nil), pa.Points...), pa.Points[0])
+	}
+	return pa.Points
+}
+
+// collapse returns pts all moved to their mean
+func collapse(pts []FPoint) []FPoint {
+	var c FPoint
+	for _, p := range pts {
+		c.X += p.X
+		c.Y += p.Y
+	}
+	c = FPoint{X: c.X / float64(len(pts)), Y: c.Y / float64(len(pts))}
+	out := make([]FPoint, len(pts))
+	for i := range out {
+		out[i] = c
+	}
+	return out
+}
+
+// resample returns n points evenly spaced along the polyline pts, keeping its ends, pts must not be empty
+func resample(pts []FPoint, n int) []FPoint {
+	if len(pts) == n {
+		return pts
+	}
+	out := make([]FPoint, n)
+	total := 0.0
+	for i := 1; i < len(pts); i++ {
+		total += Length(pts[i-1], pts[i])
+	}
+	if len(pts) == 1 || total == 0 {
+		for i := range out {
+			out[i] = pts[0]
+		}
+		return out
+	}
+	seg, done := 1, 0.0 // done is the length of the segments before seg
+	for i := range out {
+		want := total * float64(i) / float64(n-1)
+		for seg < len(pts)-1 && done+Length(pts[seg-1], pts[seg]) < want {
+			done += Length(pts[seg-1], pts[seg])
+			seg++
+		}
+		p0, p1 := pts[seg-1], pts[seg]
+		f := 0.0
+		if l := Length(p0, p1); l > 0 {
+			f = (want - done) / l
+		}
+		out[i] = FPoint{X: p0.X + (p1.X-p0.X)*f, Y: p0.Y + (p1.Y-p0.Y)*f}
+	}
+	out[n-1] = pts[len(pts)-1]
+	return out
+}
+
+// lerpColor returns the color t of the way from a to b
+func lerpColor(a, b color.RGBA, t float64) color.RGBA {
+	ch := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t))
+	}
+	return color.RGBA{ch(a.R, b.R), ch(a.G, b.G), ch(a.B, b.B), ch(a.A, b.A)}
+}
+
+// AnimateMorph calls fn with frames images of size rect of Morph stepping t evenly from 0 at frame 0, the drawing, to 1 at the last frame, to.
+// Both drawings are flipped and centered together, so the frames share one view. Neither drawing is changed
+func (drawing *Drawing) AnimateMorph(to *Drawing, rect image.Rectangle, frames int, fn FrameFunc) error {
+	if frames < 1 {
+		return errNoFrames
+	}
+	both := Drawing{Paths: append(append([]Path(nil), drawing.Paths...), to.Paths...)}
+	db := both.Bounds()
+	scale, delta := both.CenterTransform(RectBounds(rect), FPoint{X: 0.1, Y: 0.1})
+	img := image.NewRGBA(rect)
+	for i := 0; i < frames; i++ {
+		t := 1.0
+		if frames > 1 {
+			t = float64(i) / float64(frames-1)
+		}
+		m := drawing.Morph(to, t)
+		m.Traverse(nil, VFlipPt, &db) // flip within both drawings' bounds, as the transform was found before flipping
+		m.Scale(scale)
+		m.Translate(delta)
+		draw.Draw(img, img.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		m.DrawToImage(img)
+		if err := fn(i, img); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GifWriter collects animation frames into a gif, pass its Frame method as the FrameFunc and then call Encode.
+// Frames are put over white, gif has no partial alpha, and mapped to the Plan 9 palette
+type GifWriter struct {
+	Delay int // per frame, in 100ths of a second
+	gif   gif.GIF
+}
+
+// Frame adds img as the next frame, it matches FrameFunc
+func (g *GifWriter) Frame(frame int, img *image.RGBA) error {
+	p := image.NewPaletted(img.Bounds(), palette.Plan9)
+	draw.Draw(p, p.Rect, onWhite(img), img.Bounds().Min, draw.Src)
+	g.gif.Image = append(g.gif.Image, p)
+	g.gif.Delay = append(g.gif.Delay, g.Delay)
+	return nil
+}
+
+// Encode writes the frames added so far to w as a looping gif
+func (g *GifWriter) Encode(w io.Writer) error {
+	if len(g.gif.Image) == 0 {
+		return errNoFrames
+	}
+	return gif.EncodeAll(w, &g.gif)
+}
+
+// Explode returns a copy of the drawing with each path moved away from center by t times the offset of its centroid,
+// the mean of its points, from center: 0 is the original, 1 doubles every path's distance from center, negative t pulls paths in.
+// The drawing is unchanged, so stepping t over frames gives an explode or implode animation
+func (drawing *Drawing) Explode(t float64, center FPoint) *Drawing {
+	out := drawing.Clone()
+	for i := range out.Paths {
+		pa := &out.Paths[i]
+		if len(pa.Points) == 0 {
+			continue
+		}
+		var c FPoint
+		for _, p := range pa.Points {
+			c.X += p.X
+			c.Y += p.Y
+		}
+		n := float64(len(pa.Points))
+		d := FPoint{X: (c.X/n - center.X) * t, Y: (c.Y/n - center.Y) * t}
+		for j := range pa.Points {
+			pa.Points[j].X += d.X
+			pa.Points[j].Y += d.Y
+		}
+	}
+	return out
+}
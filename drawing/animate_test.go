@@ -0,0 +1,175 @@
+package drawing
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+)
+
+func TestExplode(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 1, Y: 0}, ColorBLACK)
+	drw.LineTo(FPoint{X: 3, Y: 0})
+	drw.MoveTo(FPoint{X: 0, Y: -1}, ColorBLACK)
+	drw.LineTo(FPoint{X: 0, Y: -3})
+	orig := drw.Clone()
+	if !drw.Explode(0, FPoint{}).Equal(orig, 0) {
+		t.Error("Explode(0) changed the drawing")
+	}
+	ex := drw.Explode(0.5, FPoint{})
+	want := [][]FPoint{{{X: 2, Y: 0}, {X: 4, Y: 0}}, {{X: 0, Y: -2}, {X: 0, Y: -4}}}
+	for i := range want {
+		for j, w := range want[i] {
+			if p := ex.Paths[i].Points[j]; p != w {
+				t.Errorf("path %d point %d = %v, want %v", i, j, p, w)
+			}
+		}
+	}
+	if !drw.Equal(orig, 0) {
+		t.Error("Explode changed the original")
+	}
+}
+
+func TestAnimateGrowth(t *testing.T) {
+	var drw Drawing
+	for i := 0; i < 4; i++ {
+		drw.MoveTo(FPoint{X: float64(i), Y: 0}, ColorBLACK)
+		drw.LineTo(FPoint{X: float64(i), Y: 10})
+	}
+	orig := drw.Clone()
+	rect := image.Rect(0, 0, 40, 40)
+	var counts []int
+	err := drw.AnimateGrowth(rect, 2, func(frame int, img *image.RGBA) error {
+		if frame != len(counts) {
+			t.Errorf("frame %d, want %d", frame, len(counts))
+		}
+		n := 0
+		for i := 3; i < len(img.Pix); i += 4 {
+			if img.Pix[i] != 0 {
+				n++
+			}
+		}
+		counts = append(counts, n)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 2 || counts[0] == 0 || counts[1] <= counts[0] {
+		t.Errorf("drawn pixels per frame %v, want 2 growing frames", counts)
+	}
+	want := drw.Clone().rasterize(&rect)
+	var last *image.RGBA
+	drw.AnimateGrowth(rect, 3, func(frame int, img *image.RGBA) error {
+		last = img
+		return nil
+	})
+	if !bytes.Equal(last.Pix, want.Pix) {
+		t.Error("last frame is not the whole drawing rendered")
+	}
+	if !drw.Equal(orig, 0) {
+		t.Error("AnimateGrowth changed the drawing")
+	}
+
+	stop := errors.New("stop")
+	frames := 0
+	err = drw.AnimateGrowth(rect, 5, func(frame int, img *image.RGBA) error {
+		frames++
+		return stop
+	})
+	if err != stop || frames != 1 {
+		t.Errorf("callback error gave %v after %d frames, want stop after 1", err, frames)
+	}
+	if err := drw.AnimateGrowth(rect, 0, nil); err == nil {
+		t.Error("0 frames gave no error")
+	}
+}
+
+func TestAnimateExplodeGif(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 1, Y: 0}, ColorRED)
+	drw.LineTo(FPoint{X: 3, Y: 0})
+	drw.MoveTo(FPoint{X: -1, Y: 0}, ColorBLUE)
+	drw.LineTo(FPoint{X: -3, Y: 0})
+	var g GifWriter
+	g.Delay = 5
+	if err := drw.AnimateExplode(image.Rect(0, 0, 50, 30), 4, 1, FPoint{}, g.Frame); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := g.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Image) != 4 || out.Delay[0] != 5 || out.Image[0].Bounds() != image.Rect(0, 0, 50, 30) {
+		t.Errorf("gif has %d frames, delay %v, bounds %v", len(out.Image), out.Delay, out.Image[0].Bounds())
+	}
+	if bytes.Equal(out.Image[0].Pix, out.Image[3].Pix) {
+		t.Error("exploding frames are all the same")
+	}
+	var empty GifWriter
+	if err := empty.Encode(&buf); err == nil {
+		t.Error("gif with no frames gave no error")
+	}
+}
+
+func TestMorph(t *testing.T) {
+	var from, to Drawing
+	from.MoveTo(FPoint{X: 0, Y: 0}, color.RGBA{0, 0, 0, 255})
+	from.LineTo(FPoint{X: 4, Y: 0})
+	from.MoveTo(FPoint{X: 10, Y: 10}, ColorRED) // no partner, shrinks away
+	from.LineTo(FPoint{X: 12, Y: 10})
+	to.MoveTo(FPoint{X: 0, Y: 2}, color.RGBA{200, 0, 100, 255})
+	to.LineTo(FPoint{X: 2, Y: 4})
+	to.LineTo(FPoint{X: 4, Y: 2})
+	orig := from.Clone()
+
+	if m := from.Morph(&to, 1); !near(m.Paths[0].Points, to.Paths[0].Points) || m.Paths[0].Color != to.Paths[0].Color {
+		t.Errorf("Morph(1) = %v, want %v", m.Paths[0], to.Paths[0])
+	}
+	m := from.Morph(&to, 0)
+	if !near(m.Paths[0].Points, []FPoint{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 4, Y: 0}}) {
+		t.Errorf("Morph(0) = %v, want the line resampled to 3 points", m.Paths[0].Points)
+	}
+	m = from.Morph(&to, 0.5)
+	if !near(m.Paths[0].Points, []FPoint{{X: 0, Y: 1}, {X: 2, Y: 2}, {X: 4, Y: 1}}) || m.Paths[0].Color != (color.RGBA{100, 0, 50, 255}) {
+		t.Errorf("Morph(0.5) = %v", m.Paths[0])
+	}
+	if !near(m.Paths[1].Points, []FPoint{{X: 10.5, Y: 10}, {X: 11.5, Y: 10}}) {
+		t.Errorf("unpartnered path at 0.5 = %v, want half way to its middle", m.Paths[1].Points)
+	}
+	if !from.Equal(orig, 0) {
+		t.Error("Morph changed the drawing")
+	}
+
+	var frames []*image.RGBA
+	err := from.AnimateMorph(&to, image.Rect(0, 0, 40, 40), 3, func(frame int, img *image.RGBA) error {
+		frames = append(frames, image.NewRGBA(img.Rect))
+		copy(frames[frame].Pix, img.Pix)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 3 || bytes.Equal(frames[0].Pix, frames[2].Pix) {
+		t.Errorf("got %d frames, first and last equal %v", len(frames), len(frames) == 3 && bytes.Equal(frames[0].Pix, frames[2].Pix))
+	}
+}
+
+func near(got, want []FPoint) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if Length(got[i], want[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package drawing
+
+import (
+	"image/color"
+	"math"
+)
+
+// arrowheadSpread is the angle in degrees between each arrowhead wing and the path direction
+const arrowheadSpread = 25.0
+
+// AddArrowheads appends a two-line arrowhead at the end of each path, oriented along its last segment, to show drawing direction.
+// size is the wing length, color of the arrowheads, a fully transparent color (A == 0) uses each path's own color.
+// Paths with fewer than two distinct points are skipped
+func (drawing *Drawing) AddArrowheads(size float64, color color.RGBA) {
+	n := len(drawing.Paths)
+	for i := 0; i < n; i++ {
+		pa := drawing.Paths[i]
+		if len(pa.Points) < 2 {
+			continue
+		}
+		tip := pa.Points[len(pa.Points)-1]
+		j := len(pa.Points) - 2
+		for j >= 0 && pa.Points[j] == tip {
+			j--
+		}
+		if j < 0 {
+			continue
+		}
+		back := ThetaFromPoint(tip, pa.Points[j])
+		c := color
+		if c.A == 0 {
+			c = pa.Color
+		}
+		drawing.MoveTo(PointFromTheta(tip, back+arrowheadSpread, size), c)
+		drawing.LineTo(tip)
+		drawing.LineTo(PointFromTheta(tip, back-arrowheadSpread, size))
+		drawing.Paths[len(drawing.Paths)-1].Width = pa.Width
+	}
+}
+
+// debugMarkerSize is the debug marker cross size as a fraction of the larger drawing dimension
+const debugMarkerSize = 0.02
+
+// AddDebugMarkers appends crosses at the reference points of the drawing, red at the Bounds Min and Max corners,
+// green at the bounds center and blue at the origin, to show what transforms like CenterWithMargin or Rotate did.
+// Markers are ordinary paths so they render and export anywhere
+func (drawing *Drawing) AddDebugMarkers() {
+	db := drawing.Bounds()
+	size := math.Max(db.Max.X-db.Min.X, db.Max.Y-db.Min.Y) * debugMarkerSize
+	if size == 0 {
+		size = 1
+	}
+	center := FPoint{X: (db.Min.X + db.Max.X) / 2, Y: (db.Min.Y + db.Max.Y) / 2}
+	drawing.addCross(db.Min, size, ColorRED)
+	drawing.addCross(db.Max, size, ColorRED)
+	drawing.addCross(center, size, ColorGREEN)
+	drawing.addCross(FPoint{X: 0, Y: 0}, size, ColorBLUE)
+}
+
+// addCross appends a horizontal and a vertical line of length 2*size crossing at p
+func (drawing *Drawing) addCross(p FPoint, size float64, color color.RGBA) {
+	drawing.MoveTo(FPoint{X: p.X - size, Y: p.Y}, color)
+	drawing.LineTo(FPoint{X: p.X + size, Y: p.Y})
+	drawing.MoveTo(FPoint{X: p.X, Y: p.Y - size}, color)
+	drawing.LineTo(FPoint{X: p.X, Y: p.Y + size})
+}
@@ -0,0 +1,51 @@
+package drawing
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestAddArrowheads(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorRED)
+	drw.LineTo(FPoint{X: 10, Y: 0})
+	drw.MoveTo(FPoint{X: 5, Y: 5}, ColorBLUE) // single point, skipped
+	drw.AddArrowheads(2, color.RGBA{})
+	if len(drw.Paths) != 3 {
+		t.Fatalf("got %d paths, want 3", len(drw.Paths))
+	}
+	head := drw.Paths[2]
+	if head.Color != ColorRED || len(head.Points) != 3 || head.Points[1] != (FPoint{X: 10, Y: 0}) {
+		t.Fatalf("bad arrowhead %v", head)
+	}
+	for _, p := range []FPoint{head.Points[0], head.Points[2]} {
+		if p.X >= 10 || math.Abs(Length(p, head.Points[1])-2) > 1e-9 {
+			t.Errorf("wing end %v not 2 behind the tip", p)
+		}
+	}
+	if head.Points[0].Y*head.Points[2].Y >= 0 {
+		t.Errorf("wings %v and %v not on opposite sides", head.Points[0], head.Points[2])
+	}
+}
+
+func TestAddDebugMarkers(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 10, Y: 10}, ColorBLACK)
+	drw.LineTo(FPoint{X: 60, Y: 30})
+	drw.AddDebugMarkers()
+	if len(drw.Paths) != 9 {
+		t.Fatalf("got %d paths, want 1 + 4 crosses of 2 lines", len(drw.Paths))
+	}
+	centers := []struct {
+		p FPoint
+		c color.RGBA
+	}{{FPoint{X: 10, Y: 10}, ColorRED}, {FPoint{X: 60, Y: 30}, ColorRED}, {FPoint{X: 35, Y: 20}, ColorGREEN}, {FPoint{X: 0, Y: 0}, ColorBLUE}}
+	for i, want := range centers {
+		h := drw.Paths[1+2*i]
+		mid := FPoint{X: (h.Points[0].X + h.Points[1].X) / 2, Y: h.Points[0].Y}
+		if mid != want.p || h.Color != want.c || Length(h.Points[0], h.Points[1]) != 2 {
+			t.Errorf("marker %d at %v color %v, want %v %v", i, mid, h.Color, want.p, want.c)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package drawing
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteCanvasJS writes JavaScript that strokes the paths onto the HTML5 canvas with id canvasID, each in its color as a CSS rgba() and its Width, 1 if not set.
+// Points are written as they are, in canvas pixels with y down, so flip and center the drawing first as RenderSvg does.
+// The code is a self contained block, include it in a <script> after the canvas
+func (drawing *Drawing) WriteCanvasJS(w io.Writer, canvasID string) error {
+	for i, pa := range drawing.Paths {
+		for j, p := range pa.Points {
+			if !finite(p.X) || !finite(p.Y) {
+				return fmt.Errorf("canvas: path %d point %d has invalid coordinate %v", i, j, p)
+			}
+		}
+	}
+	id, err := json.Marshal(canvasID) // a safe js string, json escapes < and > so the id cannot close the script
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "(function () {\n  const ctx = document.getElementById(%s).getContext(\"2d\");\n", id)
+	var buf []byte
+	for _, pa := range drawing.Paths {
+		if len(pa.Points) == 0 {
+			continue
+		}
+		width := 1.0
+		if pa.Width > 0 && finite(pa.Width) {
+			width = pa.Width
+		}
+		fmt.Fprintf(bw, "  ctx.beginPath();\n  ctx.strokeStyle = \"rgba(%d, %d, %d, %s)\";\n  ctx.lineWidth = %s;\n", pa.Color.R, pa.Color.G, pa.Color.B, svgFloat(float64(pa.Color.A)/255), svgFloat(width))
+		for i, p := range pa.Points {
+			buf = buf[:0]
+			if i == 0 {
+				buf = append(buf, "  ctx.moveTo("...)
+			} else {
+				buf = append(buf, "  ctx.lineTo("...)
+			}
+			buf = appendSvgFloat(buf, p.X)
+			buf = append(buf, ", "...)
+			buf = appendSvgFloat(buf, p.Y)
+			buf = append(buf, ");\n"...)
+			bw.Write(buf)
+		}
+		if pa.Closed {
+			bw.WriteString("  ctx.closePath();\n")
+		}
+		bw.WriteString("  ctx.stroke();\n")
+	}
+	bw.WriteString("})();\n")
+	return bw.Flush()
+}
@@ -0,0 +1,39 @@
+package drawing
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestWriteCanvasJS(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 1, Y: 2}, ColorRED)
+	drw.LineTo(FPoint{X: 3.25, Y: 4})
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorBLUE)
+	drw.LineTo(FPoint{X: 5, Y: 0})
+	drw.LineTo(FPoint{X: 5, Y: 5})
+	drw.Paths[1].Closed = true
+	drw.Paths[1].Width = 2.5
+	drw.Paths[1].Color.A = 128
+	var sb strings.Builder
+	if err := drw.WriteCanvasJS(&sb, `c"</script>`); err != nil {
+		t.Fatal(err)
+	}
+	js := sb.String()
+	for _, want := range []string{
+		`document.getElementById("c\"\u003c/script\u003e")`,
+		"ctx.strokeStyle = \"rgba(255, 0, 0, 1)\";\n  ctx.lineWidth = 1;\n  ctx.moveTo(1, 2);\n  ctx.lineTo(3.25, 4);\n  ctx.stroke();",
+		"rgba(0, 0, 255, 0.502)",
+		"ctx.lineWidth = 2.5;",
+		"ctx.lineTo(5, 5);\n  ctx.closePath();\n  ctx.stroke();\n})();\n",
+	} {
+		if !strings.Contains(js, want) {
+			t.Errorf("canvas js missing %q:\n%s", want, js)
+		}
+	}
+	drw.LineTo(FPoint{X: math.NaN(), Y: 0})
+	if err := drw.WriteCanvasJS(&sb, "c"); err == nil {
+		t.Error("NaN point did not fail")
+	}
+}
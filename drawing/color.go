@@ -0,0 +1,162 @@
+package drawing
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"image/color"
+	"io"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// ColorFromHSV returns the opaque RGBA color for hue h in degrees, saturation s and value v in [0, 1]
+func ColorFromHSV(h, s, v float64) color.RGBA {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h = h + 360
+	}
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := v - c
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return color.RGBA{uint8(math.Round((r + m) * 255)), uint8(math.Round((g + m) * 255)), uint8(math.Round((b + m) * 255)), 255}
+}
+
+// Rainbow gives each path a distinct hue evenly spaced around the color wheel, to show where a drawing is broken into paths
+func (drawing *Drawing) Rainbow() {
+	n := float64(len(drawing.Paths))
+	for i := range drawing.Paths {
+		drawing.Paths[i].Color = ColorFromHSV(360*float64(i)/n, 1, 1)
+	}
+}
+
+// ColorForName returns a stable color for name, its FNV-1a hash picking the hue of a saturated, slightly dark color that reads on white.
+// The same name always gets the same color, across runs and platforms, different names usually get different hues
+func ColorForName(name string) color.RGBA {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return ColorFromHSV(float64(h.Sum32()%3600)/10, 0.8, 0.85)
+}
+
+// RandomizeColors sets each path to base with every color channel moved by up to spread (0 to 1, a fraction of the full channel range).
+// base is alpha premultiplied, so the range is 0 to base.A, channels are clamped to it and alpha is kept. Draws from rng so results are reproducible, a nil rng uses a fixed seed
+func (drawing *Drawing) RandomizeColors(base color.RGBA, spread float64, rng *rand.Rand) {
+	if rng == nil {
+		rng = rand.New(rand.NewSource(1))
+	}
+	top := float64(base.A)
+	jitter := func(c uint8) uint8 {
+		v := float64(c) + (rng.Float64()*2-1)*spread*top
+		return uint8(math.Round(math.Max(0, math.Min(top, v))))
+	}
+	for i := range drawing.Paths {
+		drawing.Paths[i].Color = color.RGBA{jitter(base.R), jitter(base.G), jitter(base.B), base.A}
+	}
+}
+
+// LoadPalette reads a palette from either a GIMP .gpl file (starting with "GIMP Palette") or a list of #RRGGBB lines.
+// In a GIMP palette lines starting with # are comments, in a hex list comments start with "# ", "//" or ";". Blank lines are skipped,
+// as are a leading byte order mark and any # lines before the GIMP header, even ones that look like a hex color
+func LoadPalette(r io.Reader) ([]color.RGBA, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) > 0 {
+		lines[0] = strings.TrimPrefix(lines[0], "\uFEFF")
+	}
+	// the first line not starting with # tells the format, only then are the # lines before it known to be comments or colors
+	gimp, start := false, 0
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			gimp = line == "GIMP Palette"
+			if gimp {
+				start = i + 1
+			}
+			break
+		}
+	}
+	var palette []color.RGBA
+	for i, line := range lines[start:] {
+		n := start + i + 1
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if gimp {
+			if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "Name:") || strings.HasPrefix(line, "Columns:") {
+				continue
+			}
+			c, err := parseGimpColor(line)
+			if err != nil {
+				return nil, fmt.Errorf("palette line %d: %v", n, err)
+			}
+			palette = append(palette, c)
+			continue
+		}
+		if line == "#" || strings.HasPrefix(line, "# ") || strings.HasPrefix(line, "//") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		c, err := parseHexColor(strings.Fields(line)[0])
+		if err != nil {
+			return nil, fmt.Errorf("palette line %d: %v", n, err)
+		}
+		palette = append(palette, c)
+	}
+	if len(palette) == 0 {
+		return nil, errors.New("palette has no colors")
+	}
+	return palette, nil
+}
+
+// parseGimpColor parses a GIMP palette entry: red, green and blue 0-255 then an optional name
+func parseGimpColor(line string) (color.RGBA, error) {
+	f := strings.Fields(line)
+	if len(f) < 3 {
+		return color.RGBA{}, fmt.Errorf("want \"R G B [name]\", got %q", line)
+	}
+	var rgb [3]uint8
+	for i := range rgb {
+		v, err := strconv.ParseUint(f[i], 10, 8)
+		if err != nil {
+			return color.RGBA{}, fmt.Errorf("bad channel %q in %q, want 0-255", f[i], line)
+		}
+		rgb[i] = uint8(v)
+	}
+	return color.RGBA{rgb[0], rgb[1], rgb[2], 255}, nil
+}
+
+// parseHexColor parses an opaque #RRGGBB color
+func parseHexColor(s string) (color.RGBA, error) {
+	if len(s) != 7 || s[0] != '#' {
+		return color.RGBA{}, fmt.Errorf("want #RRGGBB, got %q", s)
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("want #RRGGBB, got %q", s)
+	}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, nil
+}
@@ -0,0 +1,154 @@
+package drawing
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestColorFromHSV(t *testing.T) {
+	cases := []struct {
+		h, s, v float64
+		want    color.RGBA
+	}{
+		{0, 1, 1, ColorRED},
+		{120, 1, 1, ColorGREEN},
+		{240, 1, 1, ColorBLUE},
+		{-120, 1, 1, ColorBLUE},
+		{60, 0, 1, ColorWHITE},
+		{300, 1, 0, ColorBLACK},
+	}
+	for _, c := range cases {
+		if got := ColorFromHSV(c.h, c.s, c.v); got != c.want {
+			t.Errorf("ColorFromHSV(%v, %v, %v) = %v, want %v", c.h, c.s, c.v, got, c.want)
+		}
+	}
+}
+
+// hue returns the hue of c in degrees [0, 360)
+func hue(c color.RGBA) float64 {
+	r, g, b := float64(c.R), float64(c.G), float64(c.B)
+	hi, lo := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	var h float64
+	switch {
+	case hi == lo:
+		return 0
+	case hi == r:
+		h = (g - b) / (hi - lo)
+	case hi == g:
+		h = 2 + (b-r)/(hi-lo)
+	default:
+		h = 4 + (r-g)/(hi-lo)
+	}
+	return math.Mod(h*60+360, 360)
+}
+
+func TestRainbow(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 7, 12} {
+		var drw Drawing
+		for i := 0; i < n; i++ {
+			drw.MoveTo(FPoint{X: float64(i)}, ColorBLACK)
+			drw.LineTo(FPoint{X: float64(i), Y: 1})
+		}
+		drw.Rainbow()
+		if len(drw.Paths) != n {
+			t.Fatalf("n %d: Rainbow left %d paths", n, len(drw.Paths))
+		}
+		seen := map[color.RGBA]bool{}
+		for i, pa := range drw.Paths {
+			if seen[pa.Color] {
+				t.Errorf("n %d: path %d repeats color %v", n, i, pa.Color)
+			}
+			seen[pa.Color] = true
+			// 8 bit channels put the hue within a degree
+			if d := math.Abs(hue(pa.Color) - 360*float64(i)/float64(n)); d > 1 {
+				t.Errorf("n %d: path %d hue %.1f, want %.1f", n, i, hue(pa.Color), 360*float64(i)/float64(n))
+			}
+		}
+	}
+}
+
+func TestLoadPalette(t *testing.T) {
+	want := []color.RGBA{{255, 0, 0, 255}, {0, 128, 255, 255}}
+	inputs := map[string]string{
+		"gimp": "GIMP Palette\nName: Test\nColumns: 2\n#\n255   0   0\tRed\n  0 128 255\tSky\n",
+		"hex":  "# my colors\n#ff0000\n\n// second\n#0080FF sky\n",
+		"bom":  "\uFEFF\n# exported palette\n#ffffff white\n\nGIMP Palette\n255 0 0\n0 128 255\n",
+	}
+	for name, in := range inputs {
+		got, err := LoadPalette(strings.NewReader(in))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+		}
+	}
+	for _, bad := range []string{"", "#ff00", "GIMP Palette\n256 0 0\n", "GIMP Palette\n1 2\n", "red\n", "#ff0000\nGIMP Palette\n"} {
+		if _, err := LoadPalette(strings.NewReader(bad)); err == nil {
+			t.Errorf("LoadPalette(%q): expected error", bad)
+		}
+	}
+}
+
+func TestRandomizeColors(t *testing.T) {
+	build := func() *Drawing {
+		var drw Drawing
+		for i := 0; i < 20; i++ {
+			drw.MoveTo(FPoint{X: float64(i), Y: 0}, ColorBLACK)
+		}
+		return &drw
+	}
+	base := color.RGBA{190, 100, 5, 200}
+	a := build()
+	a.RandomizeColors(base, 0.1, rand.New(rand.NewSource(7)))
+	b := build()
+	b.RandomizeColors(base, 0.1, rand.New(rand.NewSource(7)))
+	if !a.Equal(b, 0) {
+		t.Error("same seed gave different colors")
+	}
+	varied := false
+	for _, pa := range a.Paths {
+		c := pa.Color
+		if c.A != base.A || absDiff(c.R, base.R) > 20 || absDiff(c.G, base.G) > 20 || absDiff(c.B, base.B) > 20 {
+			t.Errorf("color %v too far from %v", c, base)
+		}
+		if c != a.Paths[0].Color {
+			varied = true
+		}
+	}
+	if !varied {
+		t.Error("all paths have the same color")
+	}
+
+	half := color.RGBA{120, 64, 10, 128} // premultiplied, no channel may pass alpha
+	a.RandomizeColors(half, 0.5, rand.New(rand.NewSource(7)))
+	for _, pa := range a.Paths {
+		if c := pa.Color; c.A != half.A || c.R > c.A || c.G > c.A || c.B > c.A {
+			t.Errorf("color %v is not a valid premultiplied color", c)
+		}
+	}
+}
+
+func absDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
+
+func TestColorForName(t *testing.T) {
+	koch := ColorForName("Koch")
+	if koch != ColorForName("Koch") || koch.A != 255 {
+		t.Fatalf("ColorForName(Koch) = %v, not stable and opaque", koch)
+	}
+	if koch == ColorForName("Koch2") || koch == ColorForName("Dragon") {
+		t.Error("different names got the same color")
+	}
+	if want := (color.RGBA{43, 93, 217, 255}); koch != want { // pinned, caches and galleries rely on it
+		t.Errorf("ColorForName(Koch) = %v, want %v", koch, want)
+	}
+}
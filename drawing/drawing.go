@@ -4,13 +4,19 @@
 package drawing
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
-	"image/png"
+	"image/jpeg"
+	"io"
 	"math"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/StephaneBunel/bresenham"
 )
@@ -32,6 +38,8 @@ type FPoint struct {
 type Path struct {
 	Points []FPoint
 	Color  color.RGBA
+	Width  float64 // stroke width for vector output, 0 for the renderer default
+	Closed bool    // the last point joins back to the first
 }
 
 // Drawing is an array of paths
@@ -48,34 +56,77 @@ type FRect struct {
 // RenderPng renders a drawing centered as a png with given filepath and given size (rect)
 // If rect is nil size defaults to 2kx2k
 func (drawing *Drawing) RenderPng(rect *image.Rectangle, filePath string) (string, error) {
-	var img *image.RGBA
-	if rect == nil {
-		rect = &image.Rectangle{}
-		*rect = image.Rect(0, 0, 2000, 2000)
-	}
-	img = image.NewRGBA(*rect)
-	ib := RectBounds(*rect)
-	// ib := ImageBounds(img)
-	drawing.Flip(true)
-	drawing.CenterWithMargin(ib, FPoint{X: 0.1, Y: 0.1}) //add a 10% of size margin
-	drawing.DrawToImage(img)
-	// flipImg := ImageFlipV(img)
+	return drawing.RenderPngWithOptions(rect, filePath, nil)
+}
+
+// RenderJpeg renders a drawing centered as a jpeg with given filepath and given size (rect)
+// jpeg has no alpha, so the drawing is rendered over a white background
+// If rect is nil size defaults to 2kx2k
+func (drawing *Drawing) RenderJpeg(rect *image.Rectangle, filePath string) (string, error) {
+	bg := onWhite(drawing.rasterize(rect))
 	toimg, err := os.Create(filePath)
 	if err != nil {
 		return filePath, err
 	}
 	defer toimg.Close()
-	err = png.Encode(toimg, img)
+	err = jpeg.Encode(toimg, bg, &jpeg.Options{Quality: 90})
 	if err != nil {
 		return filePath, err
 	}
 	return fmt.Sprintf("%s: %v paths", filePath, len(drawing.Paths)), nil
 }
 
+// Render renders a drawing centered with given size (rect), choosing png, jpeg, svg or tiff from the filePath extension
+// If rect is nil size defaults to 2kx2k
+func (drawing *Drawing) Render(rect *image.Rectangle, filePath string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".png":
+		return drawing.RenderPng(rect, filePath)
+	case ".jpg", ".jpeg":
+		return drawing.RenderJpeg(rect, filePath)
+	case ".svg":
+		return drawing.RenderSvg(rect, filePath)
+	case ".tif", ".tiff":
+		err := drawing.RenderTiff(rect, filePath, "deflate")
+		if err != nil {
+			return filePath, err
+		}
+		return fmt.Sprintf("%s: %v paths", filePath, len(drawing.Paths)), nil
+	}
+	return filePath, fmt.Errorf("unsupported output format %q for %s, use .png, .jpg, .jpeg, .svg, .tif or .tiff", filepath.Ext(filePath), filePath)
+}
+
+// rasterize flips, centers and draws the drawing into a new image of size rect
+// If rect is nil size defaults to 2kx2k
+func (drawing *Drawing) rasterize(rect *image.Rectangle) *image.RGBA {
+	if rect == nil {
+		rect = &image.Rectangle{}
+		*rect = image.Rect(0, 0, 2000, 2000)
+	}
+	img := image.NewRGBA(*rect)
+	ib := RectBounds(*rect)
+	drawing.Flip(true)
+	drawing.CenterWithMargin(ib, FPoint{X: 0.1, Y: 0.1}) //add a 10% of size margin
+	drawing.DrawToImage(img)
+	return img
+}
+
+// onWhite returns img composited over a white background, for formats without alpha
+func onWhite(img *image.RGBA) *image.RGBA {
+	bg := image.NewRGBA(img.Bounds())
+	draw.Draw(bg, bg.Bounds(), image.NewUniform(ColorWHITE), image.Point{}, draw.Src)
+	draw.Draw(bg, bg.Bounds(), img, img.Bounds().Min, draw.Over)
+	return bg
+}
+
 // RenderSvg renders a drawing centered as a svg with given filepath and given size (rect)
 // If rect is nil size defaults to 2kx2k
 func (drawing *Drawing) RenderSvg(rect *image.Rectangle, filePath string) (string, error) {
+	return drawing.RenderSvgWithOptions(rect, filePath, nil)
+}
 
+// RenderSvgWithOptions is RenderSvg with stroke attributes from opts, nil for DefaultSvgOptions
+func (drawing *Drawing) RenderSvgWithOptions(rect *image.Rectangle, filePath string, opts *SvgOptions) (string, error) {
 	if rect == nil {
 		rect = &image.Rectangle{}
 		*rect = image.Rect(0, 0, 2000, 2000)
@@ -88,7 +139,7 @@ func (drawing *Drawing) RenderSvg(rect *image.Rectangle, filePath string) (strin
 		return "", err
 	}
 	defer fSvg.Close()
-	drawing.DrawToSvg(fSvg, *rect)
+	err = drawing.DrawToSvgWithOptions(fSvg, *rect, opts)
 	if err != nil {
 		return filePath, err
 	}
@@ -111,6 +162,28 @@ func (drawing *Drawing) LineTo(point FPoint) {
 	drawing.Paths[last].Points = append(drawing.Paths[last].Points, point)
 }
 
+// Clone returns a deep copy of the drawing, so the copy can be transformed or rendered without changing the original
+func (drawing *Drawing) Clone() *Drawing {
+	c := &Drawing{Paths: make([]Path, len(drawing.Paths))}
+	for i, pa := range drawing.Paths {
+		c.Paths[i] = pa
+		c.Paths[i].Points = append([]FPoint(nil), pa.Points...)
+	}
+	return c
+}
+
+// DrawInto appends copies of all paths to dst, translated by offset, keeping colors, widths and closure. The drawing is unchanged
+func (drawing *Drawing) DrawInto(dst *Drawing, offset FPoint) {
+	for _, pa := range drawing.Paths {
+		cp := pa
+		cp.Points = make([]FPoint, len(pa.Points))
+		for i, p := range pa.Points {
+			cp.Points[i] = FPoint{X: p.X + offset.X, Y: p.Y + offset.Y}
+		}
+		dst.Paths = append(dst.Paths, cp)
+	}
+}
+
 // RectBounds returns the Floating point FRect of an image rectangle.
 // effectively converting image coordinates to drawing coordinates
 func RectBounds(rect image.Rectangle) (pb FRect) {
@@ -166,18 +239,36 @@ func BoundsPt(s ...interface{}) {
 	(*(s[1].(*FRect))).Max.Y = math.Max((*(s[1].(*FRect))).Max.Y, (*(s[0].(*FPoint))).Y)
 }
 
-// Bounds extracts bounds from all points in a drawing
+// Bounds extracts bounds from all points in a drawing, a drawing with no points has zero bounds
 func (drawing *Drawing) Bounds() (pb FRect) {
 	// fmt.Println(">>Bounds: ")
-	pb.Min.X = drawing.Paths[0].Points[0].X
-	pb.Max.X = drawing.Paths[0].Points[0].X
-	pb.Min.Y = drawing.Paths[0].Points[0].Y
-	pb.Max.Y = drawing.Paths[0].Points[0].Y
+	for _, pa := range drawing.Paths {
+		if len(pa.Points) > 0 {
+			pb.Min = pa.Points[0]
+			pb.Max = pa.Points[0]
+			break
+		}
+	}
 	drawing.Traverse(nil, BoundsPt, &pb)
 	// fmt.Println("  ...", pb)
 	return
 }
 
+// BoundsExcludingSinglePointPaths is Bounds ignoring paths of fewer than two points, such as pen position markers or a stray MoveTo,
+// so they do not skew framing. If no path has two points it is Bounds
+func (drawing *Drawing) BoundsExcludingSinglePointPaths() FRect {
+	var lines Drawing
+	for _, pa := range drawing.Paths {
+		if len(pa.Points) > 1 {
+			lines.Paths = append(lines.Paths, pa)
+		}
+	}
+	if len(lines.Paths) == 0 {
+		return drawing.Bounds()
+	}
+	return lines.Bounds()
+}
+
 // == Use Traverse to translate all points in a drawing
 
 // Translate Point function
@@ -231,6 +322,22 @@ func (drawing *Drawing) Rotate(angle float64) {
 	drawing.Traverse(nil, RotatePt, &cos, &sin)
 }
 
+// == Use Traverse to round all points in a drawing
+
+// Round Point function
+func RoundPt(s ...interface{}) {
+	factor := *(s[1].(*float64))
+	(*(s[0].(*FPoint))).X = math.Round((*(s[0].(*FPoint))).X*factor) / factor
+	(*(s[0].(*FPoint))).Y = math.Round((*(s[0].(*FPoint))).Y*factor) / factor
+}
+
+// Round all points to decimals decimal places, trimming floating point noise before text exports. Negative decimals round to tens, hundreds..
+func (drawing *Drawing) Round(decimals int) {
+	// fmt.Println(">>Round: ", decimals)
+	factor := math.Pow(10, float64(decimals))
+	drawing.Traverse(nil, RoundPt, &factor)
+}
+
 // == Use Traverse to flip all points in a drawing either Horizontally or Vertically
 
 // Vertical Flip Point function
@@ -257,24 +364,33 @@ func (drawing *Drawing) Flip(vert bool) {
 // CenterWithMargin will scale and center a drawing to tb the tb FRect with the margin tm.x, tm.y in % of total bounds. eg 0.1 = 10% boundary on each edge. So in 11x17 this is 1.1" margin in Y and 1.7" margin in X
 func (drawing *Drawing) CenterWithMargin(tb FRect, tm FPoint) {
 	// fmt.Println(">>CenterWithMargin: ", tb)
-	db := drawing.Bounds()
-	// fmt.Println("Drawing Bounds: ", db)
-	scale := math.Min(((tb.Max.X-tb.Min.X)-(tm.X*2*(tb.Max.X-tb.Min.X)))/(db.Max.X-db.Min.X), ((tb.Max.Y-tb.Min.Y)-(tm.Y*2*(tb.Max.Y-tb.Min.Y)))/(db.Max.Y-db.Min.Y))
+	scale, delta := drawing.CenterTransform(tb, tm)
 	// fmt.Println("Points before scale: ", drawing.Paths)
 	// fmt.Println("Scale: ", scale)
 	drawing.Scale(scale)
 	// fmt.Println("Points after scale: ", drawing.Paths)
+	// fmt.Println("Delta: ", delta)
+	drawing.Translate(delta)
+	// fmt.Println("Points after Translate: ", drawing.Paths)
+}
+
+// CenterTransform returns the transform CenterWithMargin applies for tb and tm, without changing the drawing:
+// each point p maps to p*scale + delta
+func (drawing *Drawing) CenterTransform(tb FRect, tm FPoint) (scale float64, delta FPoint) {
+	db := drawing.Bounds()
+	// fmt.Println("Drawing Bounds: ", db)
+	scale = math.Min(((tb.Max.X-tb.Min.X)-(tm.X*2*(tb.Max.X-tb.Min.X)))/(db.Max.X-db.Min.X), ((tb.Max.Y-tb.Min.Y)-(tm.Y*2*(tb.Max.Y-tb.Min.Y)))/(db.Max.Y-db.Min.Y))
+	if math.IsInf(scale, 0) || math.IsNaN(scale) { // a single point or empty drawing has no size to scale, just center it
+		scale = 1
+	}
 	db.Min.X = db.Min.X * scale
 	db.Max.X = db.Max.X * scale
 	db.Min.Y = db.Min.Y * scale
 	db.Max.Y = db.Max.Y * scale
-	var delta FPoint
 	//just delta.X = tb.Max.X - db-Max.X (and Y)?
 	delta.X = ((tb.Max.X + tb.Min.X) / 2.0) - ((db.Max.X + db.Min.X) / 2.0)
 	delta.Y = ((tb.Max.Y + tb.Min.Y) / 2.0) - ((db.Max.Y + db.Min.Y) / 2.0)
-	// fmt.Println("Delta: ", delta)
-	drawing.Translate(delta)
-	// fmt.Println("Points after Translate: ", drawing.Paths)
+	return
 }
 
 // == Use Traverse to render a drawing to a png
@@ -282,9 +398,16 @@ func (drawing *Drawing) CenterWithMargin(tb FRect, tm FPoint) {
 // DrawToImage Path function
 func DrawToImagePa(s ...interface{}) {
 	// fmt.Println("DrawToImgPa: ", *(s[2].(*color.RGBA)))
-	pa := *(s[0].(*Path))            // get current path
+	pa := *(s[0].(*Path)) // get current path
+	if len(pa.Points) == 0 {
+		return // nothing to draw, and no point function calls follow
+	}
 	*(s[2].(*color.RGBA)) = pa.Color // pass color to point function
 	*(s[3].(*FPoint)) = pa.Points[0] // pass fromPt to point function
+	if pa.Closed {                   // draw the closing segment, order does not matter in a raster
+		last := pa.Points[len(pa.Points)-1]
+		bresenham.Bresenham(*(s[1].(*draw.Image)), int(last.X), int(last.Y), int(pa.Points[0].X), int(pa.Points[0].Y), pa.Color)
+	}
 }
 
 // DrawToImage Point function
@@ -308,64 +431,299 @@ func (drawing *Drawing) DrawToImage(img draw.Image) {
 	drawing.Traverse(DrawToImagePa, DrawToImagePt, &img, &color, &fromPt, &db)
 }
 
-// ImageFlipV flips an image vertically
+// DrawRangeToImage draws only paths [start, end) to image, clamped to the drawing, for layers or path by path animation.
+// Points are drawn where they are, so flip and center the whole drawing first for framing that does not change with the range
+func (drawing *Drawing) DrawRangeToImage(img draw.Image, start, end int) {
+	if start < 0 {
+		start = 0
+	}
+	if end > len(drawing.Paths) {
+		end = len(drawing.Paths)
+	}
+	if start >= end {
+		return
+	}
+	sub := Drawing{Paths: drawing.Paths[start:end]}
+	sub.DrawToImage(img)
+}
+
+// ImageFlipV flips an image vertically into a new image
+// The renderers do not use it, they flip the drawing before drawing instead
 func ImageFlipV(img image.Image) *image.RGBA {
 	bnds := img.Bounds()
 	var newImg = image.NewRGBA(bnds)
 	for j := bnds.Min.Y; j < bnds.Max.Y; j++ {
 		for i := bnds.Min.X; i < bnds.Max.X; i++ {
 			c := img.At(i, j)
-			newImg.Set(i, bnds.Max.Y-j-1, c)
+			newImg.Set(i, bnds.Min.Y+bnds.Max.Y-j-1, c)
 		}
 	}
 	return newImg
 }
 
-// == Use Traverse to render a drawing to an svg
+// ImageFlipVInto flips src vertically into dst without allocating, for animation loops. dst must be the same size as src, and may be src to flip in place.
+// A dst of another size is left unchanged and an error returned
+func ImageFlipVInto(dst, src *image.RGBA) error {
+	b := src.Bounds()
+	if dst.Rect.Size() != b.Size() {
+		return fmt.Errorf("flip: dst size %v, want the src size %v", dst.Rect.Size(), b.Size())
+	}
+	w := b.Dx() * 4
+	if dst == src {
+		tmp := make([]byte, w) // one row to swap through
+		for y0, y1 := b.Min.Y, b.Max.Y-1; y0 < y1; y0, y1 = y0+1, y1-1 {
+			r0 := src.Pix[src.PixOffset(b.Min.X, y0):][:w]
+			r1 := src.Pix[src.PixOffset(b.Min.X, y1):][:w]
+			copy(tmp, r0)
+			copy(r0, r1)
+			copy(r1, tmp)
+		}
+		return nil
+	}
+	d := dst.Rect
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		copy(dst.Pix[dst.PixOffset(d.Min.X, d.Max.Y-1-(y-b.Min.Y)):][:w], src.Pix[src.PixOffset(b.Min.X, y):][:w])
+	}
+	return nil
+}
+
+// == Render a drawing to an svg
+
+// svgPointsBuf is the size of the points buffer flushed to the svg writer
+const svgPointsBuf = 4096
+
+// SvgPrecision is the number of decimals written for svg coordinates, plenty for pixel sized output
+const SvgPrecision = 3
+
+// appendSvgFloat appends f with at most SvgPrecision decimals and no trailing zeros, never in exponent form
+func appendSvgFloat(buf []byte, f float64) []byte {
+	start := len(buf)
+	buf = strconv.AppendFloat(buf, f, 'f', SvgPrecision, 64)
+	for buf[len(buf)-1] == '0' {
+		buf = buf[:len(buf)-1]
+	}
+	if buf[len(buf)-1] == '.' {
+		buf = buf[:len(buf)-1]
+	}
+	if string(buf[start:]) == "-0" {
+		buf = append(buf[:start], '0')
+	}
+	return buf
+}
+
+// svgFloat formats f as appendSvgFloat
+func svgFloat(f float64) string {
+	return string(appendSvgFloat(nil, f))
+}
+
+// finite reports whether f is neither NaN nor infinite
+func finite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// SvgOptions are the stroke attributes written on each svg polyline, a zero field uses the DefaultSvgOptions value
+type SvgOptions struct {
+	StrokeWidth float64 // width of paths that have no Width of their own, 0 for 2
+	LineCap     string  // "butt", "round" or "square", "" to omit
+	LineJoin    string  // "miter", "round" or "bevel", "" to omit
+	Opacity     float64 // stroke-opacity above 0 and up to 1, written only below 1, 0 for 1
+}
 
-// DrawToSvg Path function
-// s[0] current path
-// s[1] fSvg
+// DefaultSvgOptions returns the options used when none are given, start from it when customizing
+func DefaultSvgOptions() SvgOptions {
+	return SvgOptions{StrokeWidth: 2, Opacity: 1}
+}
+
+// DrawToSvgPa starts the svg polyline of path s[0] on the io.Writer s[1], closing the element before it, for Traverse. It panics on a write error
 func DrawToSvgPa(s ...interface{}) {
-	pa := *(s[0].(*Path))
+	pa := s[0].(*Path)
 	p := pa.Points[0]
-
-	fSvg := *(s[1].(*os.File))
-	str := fmt.Sprintf("\" />\n<polyline fill=\"none\" stroke=\"#%02x%02x%02x%02x\" stroke-width=\"2\" points=\"%v,%v", pa.Color.R, pa.Color.G, pa.Color.B, pa.Color.A, p.X, p.Y)
-	_, err := fSvg.WriteString(str)
+	w := s[1].(io.Writer)
+	_, err := fmt.Fprintf(w, "\" />\n<polyline fill=\"none\" stroke=\"#%02x%02x%02x%02x\" stroke-width=\"2\" points=\"%s,%s", pa.Color.R, pa.Color.G, pa.Color.B, pa.Color.A, svgFloat(p.X), svgFloat(p.Y))
 	if err != nil {
 		panic(err)
 	}
 }
 
-// DrawToSvg Point function
-// s[0] current point
-// s[1] fSvg
+// DrawToSvgPt adds point s[0] to the svg polyline being written to the io.Writer s[1], for Traverse. It panics on a write error
 func DrawToSvgPt(s ...interface{}) {
-	p := *(s[0].(*FPoint))
-	fSvg := *(s[1].(*os.File))
-	str := fmt.Sprintf(" %v,%v", p.X, p.Y)
-	_, err := fSvg.WriteString(str)
+	p := s[0].(*FPoint)
+	w := s[1].(io.Writer)
+	_, err := fmt.Fprintf(w, " %s,%s", svgFloat(p.X), svgFloat(p.Y))
 	if err != nil {
 		panic(err)
 	}
 }
 
-// DrawToSvg draws drawing to svg file
+// DrawToSvg draws drawing to the svg file fSvg as WriteSvg does, panicking on a write error. Use WriteSvg to get the error instead
 func (drawing *Drawing) DrawToSvg(fSvg *os.File, rect image.Rectangle) {
-	str := fmt.Sprintf("<?xml version=\"1.0\" standalone=\"no\"?>\n<svg width=\"%d\" height=\"%d\"\nxmlns=\"http://www.w3.org/2000/svg\" version=\"1.1\">\n<rect x=\"1\" y=\"1\" width=\"%v\" height=\"%v\"\nfill=\"none\" stroke=\"black\" stroke-width=\"1", rect.Max.X, rect.Max.Y, rect.Max.X, rect.Max.Y)
-	_, err := fSvg.WriteString(str)
+	err := drawing.WriteSvg(fSvg, rect)
 	if err != nil {
 		panic(err)
 	}
-	drawing.Traverse(DrawToSvgPa, DrawToSvgPt, fSvg)
-	_, err = fSvg.WriteString("\" />\n</svg>")
+}
+
+// WriteSvg draws drawing to svg on w, one polyline (polygon if Closed) per path, buffering output and formatting points without per point allocation
+func (drawing *Drawing) WriteSvg(w io.Writer, rect image.Rectangle) error {
+	return drawing.DrawToSvgWithOptions(w, rect, nil)
+}
+
+// DrawToSvgWithOptions is WriteSvg with stroke attributes from opts, nil for DefaultSvgOptions. A path's own Width overrides opts.StrokeWidth
+func (drawing *Drawing) DrawToSvgWithOptions(w io.Writer, rect image.Rectangle, opts *SvgOptions) error {
+	if opts == nil {
+		def := DefaultSvgOptions()
+		opts = &def
+	}
+	def := DefaultSvgOptions()
+	strokeWidth, opacity := opts.StrokeWidth, opts.Opacity
+	if strokeWidth == 0 {
+		strokeWidth = def.StrokeWidth
+	}
+	if opacity == 0 {
+		opacity = def.Opacity
+	}
+	// check everything first, so an invalid drawing writes nothing rather than a broken svg
+	if !finite(strokeWidth) || strokeWidth < 0 || !finite(opacity) || opacity < 0 || opacity > 1 {
+		return fmt.Errorf("svg: invalid stroke width %v or opacity %v", opts.StrokeWidth, opts.Opacity)
+	}
+	switch opts.LineCap {
+	case "", "butt", "round", "square":
+	default:
+		return fmt.Errorf("svg: invalid line cap %q, use butt, round or square", opts.LineCap)
+	}
+	switch opts.LineJoin {
+	case "", "miter", "round", "bevel":
+	default:
+		return fmt.Errorf("svg: invalid line join %q, use miter, round or bevel", opts.LineJoin)
+	}
+	for i, pa := range drawing.Paths {
+		if !finite(pa.Width) {
+			return fmt.Errorf("svg: path %d has invalid width %v", i, pa.Width)
+		}
+		for j, p := range pa.Points {
+			if !finite(p.X) || !finite(p.Y) {
+				return fmt.Errorf("svg: path %d point %d has invalid coordinate %v", i, j, p)
+			}
+		}
+	}
+	var attrs string
+	if opts.LineCap != "" {
+		attrs += fmt.Sprintf(" stroke-linecap=\"%s\"", opts.LineCap)
+	}
+	if opts.LineJoin != "" {
+		attrs += fmt.Sprintf(" stroke-linejoin=\"%s\"", opts.LineJoin)
+	}
+	if opacity < 1 {
+		attrs += fmt.Sprintf(" stroke-opacity=\"%s\"", svgFloat(opacity))
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<?xml version=\"1.0\" standalone=\"no\"?>\n<svg width=\"%d\" height=\"%d\"\nxmlns=\"http://www.w3.org/2000/svg\" version=\"1.1\">\n<rect x=\"1\" y=\"1\" width=\"%v\" height=\"%v\"\nfill=\"none\" stroke=\"black\" stroke-width=\"1\" />\n", rect.Max.X, rect.Max.Y, rect.Max.X, rect.Max.Y)
+	buf := make([]byte, 0, svgPointsBuf)
+	for _, pa := range drawing.Paths {
+		width := strokeWidth
+		if pa.Width > 0 {
+			width = pa.Width
+		}
+		element := "polyline"
+		if pa.Closed {
+			element = "polygon"
+		}
+		fmt.Fprintf(bw, "<%s fill=\"none\" stroke=\"#%02x%02x%02x%02x\" stroke-width=\"%s\"%s points=\"", element, pa.Color.R, pa.Color.G, pa.Color.B, pa.Color.A, svgFloat(width), attrs)
+		for i, p := range pa.Points {
+			if i > 0 {
+				buf = append(buf, ' ')
+			}
+			buf = appendSvgFloat(buf, p.X)
+			buf = append(buf, ',')
+			buf = appendSvgFloat(buf, p.Y)
+			if len(buf) > svgPointsBuf-64 {
+				bw.Write(buf)
+				buf = buf[:0]
+			}
+		}
+		bw.Write(buf)
+		buf = buf[:0]
+		bw.WriteString("\" />\n")
+	}
+	bw.WriteString("</svg>\n")
+	return bw.Flush() // bufio keeps the first write error, so checking Flush covers every write
+}
+
+// == Subdivide segments
+
+// Subdivide inserts evenly spaced points along every segment longer than maxLen, so that no segment exceeds maxLen.
+// Use it before transforms that move vertices so long straight segments deform smoothly.
+// Endpoints and colors are unchanged, the closing segment of a closed path is split too.
+// A segment with an Inf or NaN end is kept whole, it has no length to split.
+// Returns the new total point count, maxLen <= 0 leaves the drawing unchanged
+func (drawing *Drawing) Subdivide(maxLen float64) (points int) {
+	for i := range drawing.Paths {
+		pa := &drawing.Paths[i]
+		if maxLen > 0 && len(pa.Points) > 1 {
+			pts := []FPoint{pa.Points[0]}
+			for j := 0; j < pa.Segments(); j++ {
+				p0, p1, _ := pa.segment(j)
+				n := 0
+				if l := Length(p0, p1); finite(l) {
+					n = int(math.Ceil(l / maxLen))
+				}
+				for k := 1; k < n; k++ {
+					f := float64(k) / float64(n)
+					pts = append(pts, FPoint{X: p0.X + (p1.X-p0.X)*f, Y: p0.Y + (p1.Y-p0.Y)*f})
+				}
+				if j < len(pa.Points)-1 { // the closing segment ends at the first point, already there
+					pts = append(pts, p1)
+				}
+			}
+			pa.Points = pts
+		}
+		points += len(pa.Points)
+	}
+	return
+}
+
+// == Serialization and comparison
+
+// WriteJSON writes the drawing as JSON, paths and points in drawing order, so output is stable for golden files
+func (drawing *Drawing) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	return enc.Encode(drawing)
+}
+
+// ReadJSON reads a drawing written by WriteJSON
+func ReadJSON(r io.Reader) (*Drawing, error) {
+	var drw Drawing
+	err := json.NewDecoder(r).Decode(&drw)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	return &drw, nil
+}
+
+// Equal reports whether two drawings have the same paths, colors, widths, closure and points, with each point coordinate within tolerance
+func (drawing *Drawing) Equal(other *Drawing, tolerance float64) bool {
+	if len(drawing.Paths) != len(other.Paths) {
+		return false
+	}
+	for i, pa := range drawing.Paths {
+		pb := other.Paths[i]
+		if pa.Color != pb.Color || pa.Width != pb.Width || pa.Closed != pb.Closed || len(pa.Points) != len(pb.Points) {
+			return false
+		}
+		for j, p := range pa.Points {
+			if math.Abs(p.X-pb.Points[j].X) > tolerance || math.Abs(p.Y-pb.Points[j].Y) > tolerance {
+				return false
+			}
+		}
 	}
+	return true
 }
 
 // General functions
+//
+// All angles are in degrees, measured counter-clockwise from the +X axis.
+// PointFromTheta and ThetaFromPoint share this convention, so that
+// ThetaFromPoint(p0, PointFromTheta(p0, theta, length)) == theta (mod 360) for any length > 0
 
 func ToRadians(degrees float64) float64 {
 	return degrees * (math.Pi / 180.0)
@@ -380,19 +738,14 @@ func Length(p0, p1 FPoint) (length float64) {
 	return math.Sqrt((p1.X-p0.X)*(p1.X-p0.X) + (p1.Y-p0.Y)*(p1.Y-p0.Y))
 }
 
-// Return angle (degrees) from 2 points p0->p1
+// Return angle (degrees) from 2 points p0->p1, normalized to [0, 360)
 func ThetaFromPoint(p0, p1 FPoint) (theta float64) {
-	if (p1.X - p0.X) == 0 {
-		if p1.Y > p0.Y {
-			theta = 90
-		} else {
-			theta = 270
-		}
-	} else {
-		theta = ToDegrees(math.Atan((p1.Y - p0.Y) / (p1.X - p0.X)))
+	theta = ToDegrees(math.Atan2(p1.Y-p0.Y, p1.X-p0.X))
+	if theta < 0 {
+		theta = theta + 360
 	}
-	if p0.X > p1.X {
-		theta = theta + 180
+	if theta >= 360 { // tiny negative angles round up to 360
+		theta = 0
 	}
 	return
 }
@@ -0,0 +1,415 @@
+package drawing
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// angleDiff returns the smallest absolute difference between two angles in degrees
+func angleDiff(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	return math.Min(d, 360-d)
+}
+
+func TestThetaRoundTrip(t *testing.T) {
+	origins := []FPoint{{X: 0, Y: 0}, {X: 3.5, Y: -2}, {X: -100, Y: 42}}
+	lengths := []float64{0.01, 1, 250}
+	for _, p0 := range origins {
+		for _, length := range lengths {
+			for theta := -720.0; theta <= 720.0; theta += 7.5 {
+				got := ThetaFromPoint(p0, PointFromTheta(p0, theta, length))
+				if got < 0 || got >= 360 {
+					t.Errorf("ThetaFromPoint(%v, θ=%v, L=%v) = %v, want in [0, 360)", p0, theta, length, got)
+				}
+				if angleDiff(got, theta) > 1e-6 {
+					t.Errorf("ThetaFromPoint(%v, θ=%v, L=%v) = %v, want %v (mod 360)", p0, theta, length, got, math.Mod(theta+720, 360))
+				}
+			}
+		}
+	}
+}
+
+func TestThetaFromPointAxes(t *testing.T) {
+	p0 := FPoint{X: 1, Y: 1}
+	cases := []struct {
+		p1   FPoint
+		want float64
+	}{
+		{FPoint{X: 2, Y: 1}, 0},
+		{FPoint{X: 1, Y: 2}, 90},
+		{FPoint{X: 0, Y: 1}, 180},
+		{FPoint{X: 1, Y: 0}, 270},
+		{FPoint{X: 2, Y: 0}, 315},
+	}
+	for _, c := range cases {
+		if got := ThetaFromPoint(p0, c.p1); angleDiff(got, c.want) > 1e-9 {
+			t.Errorf("ThetaFromPoint(%v, %v) = %v, want %v", p0, c.p1, got, c.want)
+		}
+	}
+}
+
+func TestDrawToSvgPolylinePerPath(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorRED)
+	drw.LineTo(FPoint{X: 1.5, Y: 2})
+	drw.MoveTo(FPoint{X: 3, Y: 3}, ColorBLUE)
+	drw.LineTo(FPoint{X: 4, Y: 5})
+	var buf bytes.Buffer
+	err := drw.WriteSvg(&buf, image.Rect(0, 0, 100, 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if n := strings.Count(out, "<polyline"); n != 2 {
+		t.Errorf("got %d polylines, want 2", n)
+	}
+	if !strings.Contains(out, `stroke="#ff0000ff" stroke-width="2" points="0,0 1.5,2" />`) {
+		t.Errorf("missing first path polyline in:\n%s", out)
+	}
+	if !strings.HasSuffix(out, "</svg>\n") {
+		t.Errorf("svg not closed:\n%s", out)
+	}
+}
+
+func TestDrawToSvgFile(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorRED)
+	drw.LineTo(FPoint{X: 1.5, Y: 2})
+	rect := image.Rect(0, 0, 100, 100)
+	path := filepath.Join(t.TempDir(), "t.svg")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drw.DrawToSvg(f, rect)
+	f.Close()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want bytes.Buffer
+	drw.WriteSvg(&want, rect)
+	if string(got) != want.String() {
+		t.Errorf("DrawToSvg wrote\n%s\nwant\n%s", got, want.String())
+	}
+	var buf bytes.Buffer
+	drw.Traverse(DrawToSvgPa, DrawToSvgPt, &buf)
+	if out := buf.String(); out != `" />`+"\n"+`<polyline fill="none" stroke="#ff0000ff" stroke-width="2" points="0,0 0,0 1.5,2` {
+		t.Errorf("DrawToSvgPa and DrawToSvgPt wrote %q", out)
+	}
+}
+
+func BenchmarkDrawToSvg(b *testing.B) {
+	// a random walk the size of a deep fractal, in paths of 1000 points
+	var drw Drawing
+	rnd := rand.New(rand.NewSource(1))
+	var p FPoint
+	for i := 0; i < 100000; i++ {
+		if i%1000 == 0 {
+			drw.MoveTo(p, ColorBLACK)
+		}
+		p = PointFromTheta(p, rnd.Float64()*360, 1)
+		drw.LineTo(p)
+	}
+	rect := image.Rect(0, 0, 1024, 1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := drw.WriteSvg(io.Discard, rect)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestRenderByExtension(t *testing.T) {
+	dir := t.TempDir()
+	rect := image.Rect(0, 0, 64, 64)
+	for _, name := range []string{"a.png", "b.JPG", "c.jpeg", "d.svg", "e.tiff"} {
+		var drw Drawing
+		drw.MoveTo(FPoint{X: 0, Y: 0}, ColorBLACK)
+		drw.LineTo(FPoint{X: 1, Y: 1})
+		_, err := drw.Render(&rect, filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorBLACK)
+	drw.LineTo(FPoint{X: 1, Y: 1})
+	if _, err := drw.Render(&rect, filepath.Join(dir, "e.bmp")); err == nil {
+		t.Error("expected error for .bmp")
+	}
+}
+
+func TestSubdivide(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorRED)
+	drw.LineTo(FPoint{X: 10, Y: 0})
+	drw.LineTo(FPoint{X: 10, Y: 0.5})
+	n := drw.Subdivide(3)
+	pts := drw.Paths[0].Points
+	if n != 6 || len(pts) != 6 {
+		t.Fatalf("got %d points (reported %d), want 6", len(pts), n)
+	}
+	for i := 1; i < len(pts); i++ {
+		if l := Length(pts[i-1], pts[i]); l > 3+1e-9 {
+			t.Errorf("segment %d has length %v > 3", i, l)
+		}
+	}
+	if pts[0] != (FPoint{X: 0, Y: 0}) || pts[len(pts)-1] != (FPoint{X: 10, Y: 0.5}) {
+		t.Errorf("endpoints changed: %v", pts)
+	}
+	if drw.Paths[0].Color != ColorRED {
+		t.Errorf("color changed to %v", drw.Paths[0].Color)
+	}
+
+	closed := Drawing{Paths: []Path{{Points: []FPoint{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 3}}, Closed: true}}}
+	n = closed.Subdivide(2)
+	pa := closed.Paths[0]
+	if n != 7 || len(pa.Points) != 7 { // 2 + 2 segments on the sides and 3 on the closing one, 5 long
+		t.Fatalf("closed path got %d points (reported %d), want 7", len(pa.Points), n)
+	}
+	for i := 0; i < pa.Segments(); i++ {
+		if l := pa.SegmentLength(i); l > 2+1e-9 {
+			t.Errorf("closed path segment %d has length %v > 2", i, l)
+		}
+	}
+
+	bad := Drawing{Paths: []Path{{Points: []FPoint{{X: 0, Y: 0}, {X: math.Inf(1), Y: 0}, {X: math.NaN(), Y: 1}, {X: 0, Y: 2}, {X: 0, Y: 4}}}}}
+	if n = bad.Subdivide(1); n != 6 { // only the last segment, from (0,2) to (0,4), is split
+		t.Errorf("non finite path got %d points, want 6", n)
+	}
+}
+
+func TestCenterTransform(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 1, Y: 1}, ColorBLACK)
+	drw.LineTo(FPoint{X: 3, Y: 2})
+	tb := FRect{Min: FPoint{X: 0, Y: 0}, Max: FPoint{X: 100, Y: 100}}
+	tm := FPoint{X: 0.1, Y: 0.1}
+	scale, delta := drw.CenterTransform(tb, tm)
+	if drw.Paths[0].Points[0] != (FPoint{X: 1, Y: 1}) {
+		t.Fatal("CenterTransform changed the drawing")
+	}
+	if scale != 40 || delta != (FPoint{X: -30, Y: -10}) {
+		t.Errorf("got scale %v delta %v, want 40 {-30 -10}", scale, delta)
+	}
+	drw.CenterWithMargin(tb, tm)
+	got := drw.Paths[0].Points[1]
+	if want := (FPoint{X: 3*scale + delta.X, Y: 2*scale + delta.Y}); got != want {
+		t.Errorf("CenterWithMargin moved %v, CenterTransform gives %v", got, want)
+	}
+}
+
+func TestDrawToSvgWithOptions(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorRED)
+	drw.LineTo(FPoint{X: 1, Y: 1})
+	drw.MoveTo(FPoint{X: 2, Y: 2}, ColorRED)
+	drw.LineTo(FPoint{X: 3, Y: 3})
+	drw.Paths[1].Width = 5
+	opts := DefaultSvgOptions()
+	opts.StrokeWidth = 0.5
+	opts.LineCap = "round"
+	opts.Opacity = 0.25
+	var buf bytes.Buffer
+	err := drw.DrawToSvgWithOptions(&buf, image.Rect(0, 0, 10, 10), &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`stroke-width="0.5" stroke-linecap="round" stroke-opacity="0.25" points="0,0 1,1"`,
+		`stroke-width="5" stroke-linecap="round" stroke-opacity="0.25" points="2,2 3,3"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	err = drw.DrawToSvgWithOptions(&buf, image.Rect(0, 0, 10, 10), &SvgOptions{LineJoin: "bevel"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `stroke-width="2" stroke-linejoin="bevel" points="0,0 1,1"`; !strings.Contains(buf.String(), want) {
+		t.Errorf("zero options did not use the defaults, missing %s in:\n%s", want, buf.String())
+	}
+	for _, bad := range []SvgOptions{{LineCap: `round" onload="x`}, {LineJoin: "arcs"}, {Opacity: 1.5}, {Opacity: -1}, {StrokeWidth: -1}} {
+		buf.Reset()
+		if err := drw.DrawToSvgWithOptions(&buf, image.Rect(0, 0, 10, 10), &bad); err == nil || buf.Len() != 0 {
+			t.Errorf("%+v gave error %v and wrote %d bytes, want an error and nothing", bad, err, buf.Len())
+		}
+	}
+}
+
+func TestClosedPath(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorRED)
+	drw.LineTo(FPoint{X: 10, Y: 0})
+	drw.LineTo(FPoint{X: 10, Y: 10})
+	drw.Paths[0].Closed = true
+	var buf bytes.Buffer
+	err := drw.WriteSvg(&buf, image.Rect(0, 0, 20, 20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "<polygon ") || strings.Contains(out, "<polyline ") {
+		t.Errorf("closed path not written as a polygon:\n%s", out)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	drw.DrawToImage(img)
+	if img.RGBAAt(5, 5) != ColorRED {
+		t.Error("closing segment not drawn")
+	}
+}
+
+func TestRenderEmpty(t *testing.T) {
+	rect := image.Rect(0, 0, 16, 16)
+	var empty Drawing
+	if b := empty.Bounds(); b != (FRect{}) {
+		t.Errorf("empty Bounds = %v, want zero", b)
+	}
+	if _, err := empty.Render(&rect, filepath.Join(t.TempDir(), "empty.png")); err != nil {
+		t.Error(err)
+	}
+	var dot Drawing
+	dot.MoveTo(FPoint{X: 3, Y: 4}, ColorBLACK)
+	if _, err := dot.Render(&rect, filepath.Join(t.TempDir(), "dot.png")); err != nil {
+		t.Error(err)
+	}
+	if p := dot.Paths[0].Points[0]; p != (FPoint{X: 7.5, Y: 7.5}) {
+		t.Errorf("single point centered at %v, want {7.5 7.5}", p)
+	}
+}
+
+func TestDrawInto(t *testing.T) {
+	var src Drawing
+	src.MoveTo(FPoint{X: 1, Y: 1}, ColorRED)
+	src.LineTo(FPoint{X: 2, Y: 1})
+	src.Paths[0].Width = 3
+	var dst Drawing
+	dst.MoveTo(FPoint{X: 0, Y: 0}, ColorBLUE)
+	src.DrawInto(&dst, FPoint{X: 10, Y: -1})
+	if len(dst.Paths) != 2 {
+		t.Fatalf("got %d paths, want 2", len(dst.Paths))
+	}
+	got := dst.Paths[1]
+	if got.Color != ColorRED || got.Width != 3 || got.Points[0] != (FPoint{X: 11, Y: 0}) || got.Points[1] != (FPoint{X: 12, Y: 0}) {
+		t.Errorf("bad copied path %+v", got)
+	}
+	dst.Paths[1].Points[0].X = 99
+	if src.Paths[0].Points[0].X != 1 {
+		t.Error("DrawInto aliases source points")
+	}
+}
+
+func TestDrawRangeToImage(t *testing.T) {
+	var drw Drawing
+	for i := 0; i < 3; i++ {
+		drw.MoveTo(FPoint{X: float64(i * 4), Y: 0}, ColorRED)
+		drw.LineTo(FPoint{X: float64(i * 4), Y: 5})
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 12, 6))
+	drw.DrawRangeToImage(img, 1, 10)
+	if img.RGBAAt(0, 2) == ColorRED || img.RGBAAt(4, 2) != ColorRED || img.RGBAAt(8, 2) != ColorRED {
+		t.Errorf("wrong paths drawn")
+	}
+	drw.DrawRangeToImage(img, 2, 1) // empty range draws nothing
+}
+
+func TestDrawToSvgInvalidCoordinate(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorBLACK)
+	drw.LineTo(FPoint{X: math.Inf(1), Y: 1})
+	var buf bytes.Buffer
+	if err := drw.WriteSvg(&buf, image.Rect(0, 0, 10, 10)); err == nil {
+		t.Error("expected an error for an Inf coordinate")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("invalid drawing wrote %q", buf.String())
+	}
+	drw.Paths[0].Points[1] = FPoint{X: math.NaN(), Y: 1}
+	if err := drw.WriteSvg(&buf, image.Rect(0, 0, 10, 10)); err == nil {
+		t.Error("expected an error for a NaN coordinate")
+	}
+}
+
+func TestSvgFloat(t *testing.T) {
+	cases := map[float64]string{0: "0", 1.5: "1.5", 2: "2", 1e-7: "0", -0.0001: "0", 1e9: "1000000000", 1.0 / 3: "0.333", -2.25: "-2.25"}
+	for f, want := range cases {
+		if got := svgFloat(f); got != want {
+			t.Errorf("svgFloat(%v) = %q, want %q", f, got, want)
+		}
+	}
+}
+
+func TestImageFlipVInto(t *testing.T) {
+	src := image.NewRGBA(image.Rect(1, 2, 4, 7))
+	for y := 2; y < 7; y++ {
+		src.SetRGBA(1, y, color.RGBA{uint8(y), 0, 0, 255})
+	}
+	want := ImageFlipV(src)
+	dst := image.NewRGBA(src.Bounds())
+	if err := ImageFlipVInto(dst, src); err != nil || !bytes.Equal(dst.Pix, want.Pix) {
+		t.Errorf("ImageFlipVInto differs from ImageFlipV, %v", err)
+	}
+	moved := image.NewRGBA(image.Rect(0, 0, 3, 5)) // same size at another origin
+	if err := ImageFlipVInto(moved, src); err != nil || !bytes.Equal(moved.Pix, want.Pix) {
+		t.Errorf("ImageFlipVInto to another origin differs from ImageFlipV, %v", err)
+	}
+	small := image.NewRGBA(image.Rect(1, 2, 4, 6))
+	if err := ImageFlipVInto(small, src); err == nil {
+		t.Error("smaller dst gave no error")
+	}
+	if err := ImageFlipVInto(src, src); err != nil || !bytes.Equal(src.Pix, want.Pix) {
+		t.Errorf("in place ImageFlipVInto differs from ImageFlipV, %v", err)
+	}
+}
+
+func TestRound(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 1.23456, Y: -0.00049}, ColorBLACK)
+	drw.LineTo(FPoint{X: 1.0 / 3, Y: 2.9999999})
+	orig := drw.Clone()
+	drw.Round(3)
+	want := []FPoint{{X: 1.235, Y: 0}, {X: 0.333, Y: 3}}
+	for i, p := range drw.Paths[0].Points {
+		if math.Abs(p.X-want[i].X) > 1e-12 || math.Abs(p.Y-want[i].Y) > 1e-12 {
+			t.Errorf("point %d = %v, want %v", i, p, want[i])
+		}
+	}
+	if !drw.Equal(orig, 0.0005) {
+		t.Error("rounding moved points by more than half a unit")
+	}
+	once := drw.Clone()
+	drw.Round(3)
+	if !drw.Equal(once, 0) {
+		t.Error("Round is not idempotent")
+	}
+}
+
+func TestBoundsExcludingSinglePointPaths(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: -100, Y: 50}, ColorBLACK) // a marker far from the lines
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorBLACK)
+	drw.LineTo(FPoint{X: 4, Y: 2})
+	if got, want := drw.BoundsExcludingSinglePointPaths(), (FRect{Max: FPoint{X: 4, Y: 2}}); got != want {
+		t.Errorf("BoundsExcludingSinglePointPaths = %v, want %v", got, want)
+	}
+	if got := drw.Bounds(); got.Min.X != -100 || got.Max.Y != 50 {
+		t.Errorf("Bounds = %v, want the marker included", got)
+	}
+	markers := Drawing{Paths: drw.Paths[:1]}
+	if got := markers.BoundsExcludingSinglePointPaths(); got != markers.Bounds() {
+		t.Errorf("markers only = %v, want Bounds %v", got, markers.Bounds())
+	}
+}
@@ -0,0 +1,55 @@
+package drawing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// geoJSONFeature is a GeoJSON Feature holding one path
+type geoJSONFeature struct {
+	Type       string         `json:"type"`
+	Geometry   geoJSONGeom    `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// geoJSONGeom is a GeoJSON LineString, or a Point for a single point path
+type geoJSONGeom struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// WriteGeoJSON writes the drawing as a GeoJSON (RFC 7946) FeatureCollection with a LineString Feature per path, in path order.
+// Coordinates are the FPoint X and Y as they are, a closed path repeats its first point at the end, a single point path is a Point
+// and an empty path is left out. The properties hold the path color as "color" (#rrggbb) and "opacity" (0 to 1), and "width" if set
+func (drawing *Drawing) WriteGeoJSON(w io.Writer) error {
+	features := make([]geoJSONFeature, 0, len(drawing.Paths))
+	for _, pa := range drawing.Paths {
+		if len(pa.Points) == 0 {
+			continue
+		}
+		props := map[string]any{
+			"color":   fmt.Sprintf("#%02x%02x%02x", pa.Color.R, pa.Color.G, pa.Color.B),
+			"opacity": float64(pa.Color.A) / 255,
+		}
+		if pa.Width > 0 {
+			props["width"] = pa.Width
+		}
+		geom := geoJSONGeom{Type: "Point", Coordinates: [2]float64{pa.Points[0].X, pa.Points[0].Y}}
+		if len(pa.Points) > 1 {
+			coords := make([][2]float64, 0, len(pa.Points)+1)
+			for _, p := range pa.Points {
+				coords = append(coords, [2]float64{p.X, p.Y})
+			}
+			if pa.Closed {
+				coords = append(coords, coords[0])
+			}
+			geom = geoJSONGeom{Type: "LineString", Coordinates: coords}
+		}
+		features = append(features, geoJSONFeature{Type: "Feature", Geometry: geom, Properties: props})
+	}
+	return json.NewEncoder(w).Encode(struct {
+		Type     string           `json:"type"`
+		Features []geoJSONFeature `json:"features"`
+	}{"FeatureCollection", features})
+}
@@ -0,0 +1,54 @@
+package drawing
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestWriteGeoJSON(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorRED)
+	drw.LineTo(FPoint{X: 1, Y: 2})
+	drw.LineTo(FPoint{X: 3, Y: 0})
+	drw.Paths[0].Closed = true
+	drw.Paths[0].Width = 2
+	drw.MoveTo(FPoint{X: 5, Y: 5}, ColorBLUE)
+	drw.Paths = append(drw.Paths, Path{})
+	var buf bytes.Buffer
+	if err := drw.WriteGeoJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var fc struct {
+		Type     string
+		Features []struct {
+			Type     string
+			Geometry struct {
+				Type        string
+				Coordinates json.RawMessage
+			}
+			Properties map[string]any
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &fc); err != nil {
+		t.Fatal(err)
+	}
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 2 {
+		t.Fatalf("%s with %d features, want a FeatureCollection of 2", fc.Type, len(fc.Features))
+	}
+	line := fc.Features[0]
+	if line.Type != "Feature" || line.Geometry.Type != "LineString" || string(line.Geometry.Coordinates) != "[[0,0],[1,2],[3,0],[0,0]]" {
+		t.Errorf("closed path feature %s %s %s", line.Type, line.Geometry.Type, line.Geometry.Coordinates)
+	}
+	if line.Properties["color"] != "#ff0000" || line.Properties["opacity"] != 1.0 || line.Properties["width"] != 2.0 {
+		t.Errorf("properties %v", line.Properties)
+	}
+	if pt := fc.Features[1]; pt.Geometry.Type != "Point" || string(pt.Geometry.Coordinates) != "[5,5]" || pt.Properties["width"] != nil {
+		t.Errorf("single point feature %s %s %v", pt.Geometry.Type, pt.Geometry.Coordinates, pt.Properties)
+	}
+	drw.LineTo(FPoint{X: math.Inf(1), Y: 0})
+	if err := drw.WriteGeoJSON(&buf); err == nil {
+		t.Error("an infinite coordinate did not fail")
+	}
+}
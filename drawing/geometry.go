@@ -0,0 +1,368 @@
+package drawing
+
+import "math"
+
+// SegmentDistance returns the distance from pt to the closest point of the segment p0->p1
+func SegmentDistance(pt, p0, p1 FPoint) float64 {
+	dx := p1.X - p0.X
+	dy := p1.Y - p0.Y
+	l2 := dx*dx + dy*dy
+	if l2 == 0 {
+		return Length(pt, p0)
+	}
+	t := math.Max(0, math.Min(1, ((pt.X-p0.X)*dx+(pt.Y-p0.Y)*dy)/l2))
+	return Length(pt, FPoint{X: p0.X + t*dx, Y: p0.Y + t*dy})
+}
+
+// ContainsPoint reports whether the stroke of the path passes within tolerance of pt.
+// A single point path contains points within tolerance of that point, a closed path includes its closing segment
+func (p *Path) ContainsPoint(pt FPoint, tolerance float64) bool {
+	if len(p.Points) == 1 {
+		return Length(pt, p.Points[0]) <= tolerance
+	}
+	for i := 0; i < p.Segments(); i++ {
+		p0, p1, _ := p.segment(i)
+		if SegmentDistance(pt, p0, p1) <= tolerance {
+			return true
+		}
+	}
+	return false
+}
+
+// HitTest returns the index of the topmost (last drawn) path whose stroke passes within tolerance of pt, ok is false if none does
+func (drawing *Drawing) HitTest(pt FPoint, tolerance float64) (pathIndex int, ok bool) {
+	for i := len(drawing.Paths) - 1; i >= 0; i-- {
+		if drawing.Paths[i].ContainsPoint(pt, tolerance) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// AspectRatio returns the width/height of the drawing Bounds, +Inf when the height is zero
+func (drawing *Drawing) AspectRatio() float64 {
+	db := drawing.Bounds()
+	h := db.Max.Y - db.Min.Y
+	if h == 0 {
+		return math.Inf(1)
+	}
+	return (db.Max.X - db.Min.X) / h
+}
+
+// TotalLength returns the drawn length of all paths, including the closing segment of closed paths
+func (drawing *Drawing) TotalLength() (length float64) {
+	for _, pa := range drawing.Paths {
+		length += pa.length()
+	}
+	return
+}
+
+// TravelLength returns the pen up distance from the end of each path to the start of the next, in drawing order, empty paths are skipped
+func (drawing *Drawing) TravelLength() (length float64) {
+	drawing.eachTravel(func(p0, p1 FPoint) {
+		length += Length(p0, p1)
+	})
+	return
+}
+
+// length returns the drawn length of the path, including the closing segment if closed
+func (p *Path) length() (length float64) {
+	for i := 1; i < len(p.Points); i++ {
+		length += Length(p.Points[i-1], p.Points[i])
+	}
+	if p.Closed && len(p.Points) > 1 {
+		length += Length(p.Points[len(p.Points)-1], p.Points[0])
+	}
+	return
+}
+
+// Segments returns the number of segments of the path, including the closing segment if closed
+func (p *Path) Segments() int {
+	n := len(p.Points) - 1
+	if n < 0 {
+		return 0
+	}
+	if p.Closed && n > 0 {
+		n++
+	}
+	return n
+}
+
+// segment returns the end points of segment i, the closing segment is the last of a closed path
+func (p *Path) segment(i int) (p0, p1 FPoint, ok bool) {
+	if i < 0 || i >= p.Segments() {
+		return
+	}
+	return p.Points[i], p.Points[(i+1)%len(p.Points)], true
+}
+
+// SegmentLength returns the length of segment i, from point i to point i+1, or NaN if there is no segment i
+func (p *Path) SegmentLength(i int) float64 {
+	p0, p1, ok := p.segment(i)
+	if !ok {
+		return math.NaN()
+	}
+	return Length(p0, p1)
+}
+
+// SegmentAngle returns the heading of segment i in degrees, see ThetaFromPoint, or NaN if there is no segment i
+func (p *Path) SegmentAngle(i int) float64 {
+	p0, p1, ok := p.segment(i)
+	if !ok {
+		return math.NaN()
+	}
+	return ThetaFromPoint(p0, p1)
+}
+
+// SegmentLengthStats returns the shortest, longest and mean length over the segments of all paths, 0s if there are none.
+// Zero length segments count in max and mean but not in min unless every segment is zero, so min shows the shortest real move, for finding segments below a plotter's resolution
+func (drawing *Drawing) SegmentLengthStats() (min, max, mean float64) {
+	n := 0
+	min = math.Inf(1)
+	for i := range drawing.Paths {
+		p := &drawing.Paths[i]
+		for j := 0; j < p.Segments(); j++ {
+			l := p.SegmentLength(j)
+			if l > 0 && l < min {
+				min = l
+			}
+			if l > max {
+				max = l
+			}
+			mean += l
+			n++
+		}
+	}
+	if n == 0 || math.IsInf(min, 1) { // no segments, or all zero length
+		return 0, max, mean / math.Max(float64(n), 1)
+	}
+	return min, max, mean / float64(n)
+}
+
+// travelSegment returns the pen up move from the end of path a to the start of path b, a closed path ends where it starts
+func travelSegment(a, b Path) (p0, p1 FPoint) {
+	p0 = a.Points[len(a.Points)-1]
+	if a.Closed {
+		p0 = a.Points[0]
+	}
+	return p0, b.Points[0]
+}
+
+// eachTravel calls fn with the travelSegment between each pair of consecutive paths that have points, skipping empty paths
+func (drawing *Drawing) eachTravel(fn func(p0, p1 FPoint)) {
+	prev := -1
+	for i := range drawing.Paths {
+		if len(drawing.Paths[i].Points) == 0 {
+			continue
+		}
+		if prev >= 0 {
+			fn(travelSegment(drawing.Paths[prev], drawing.Paths[i]))
+		}
+		prev = i
+	}
+}
+
+// DrawingStats summarizes a drawing
+type DrawingStats struct {
+	Paths        int     // number of paths
+	Points       int     // total points in all paths
+	DrawLength   float64 // TotalLength
+	TravelLength float64 // TravelLength
+	Bounds       FRect   // Bounds
+}
+
+// Stats returns the path and point counts, draw and travel lengths and bounds of the drawing in one pass
+func (drawing *Drawing) Stats() (st DrawingStats) {
+	st.Paths = len(drawing.Paths)
+	first := true
+	prev := -1
+	for i, pa := range drawing.Paths {
+		if len(pa.Points) == 0 {
+			continue
+		}
+		st.Points += len(pa.Points)
+		st.DrawLength += pa.length()
+		if prev >= 0 {
+			st.TravelLength += Length(travelSegment(drawing.Paths[prev], pa))
+		}
+		prev = i
+		for _, p := range pa.Points {
+			if first {
+				st.Bounds = FRect{Min: p, Max: p}
+				first = false
+			}
+			st.Bounds.Min.X = math.Min(st.Bounds.Min.X, p.X)
+			st.Bounds.Max.X = math.Max(st.Bounds.Max.X, p.X)
+			st.Bounds.Min.Y = math.Min(st.Bounds.Min.Y, p.Y)
+			st.Bounds.Max.Y = math.Max(st.Bounds.Max.Y, p.Y)
+		}
+	}
+	return
+}
+
+// clipSegment clips p0->p1 to rect with Liang-Barsky, returning the visible part and whether any of it is visible
+func clipSegment(p0, p1 FPoint, rect FRect) (c0, c1 FPoint, ok bool) {
+	dx := p1.X - p0.X
+	dy := p1.Y - p0.Y
+	t0, t1 := 0.0, 1.0
+	edges := [4][2]float64{
+		{-dx, p0.X - rect.Min.X},
+		{dx, rect.Max.X - p0.X},
+		{-dy, p0.Y - rect.Min.Y},
+		{dy, rect.Max.Y - p0.Y},
+	}
+	for _, e := range edges {
+		p, q := e[0], e[1]
+		if p == 0 {
+			if q < 0 {
+				return c0, c1, false // parallel and outside
+			}
+			continue
+		}
+		r := q / p
+		if p < 0 {
+			t0 = math.Max(t0, r)
+		} else {
+			t1 = math.Min(t1, r)
+		}
+		if t0 > t1 {
+			return c0, c1, false
+		}
+	}
+	c0 = p0
+	if t0 > 0 {
+		c0 = FPoint{X: p0.X + t0*dx, Y: p0.Y + t0*dy}
+	}
+	c1 = p1
+	if t1 < 1 {
+		c1 = FPoint{X: p0.X + t1*dx, Y: p0.Y + t1*dy}
+	}
+	return c0, c1, true
+}
+
+// Clip removes everything outside rect, cutting segments at its edges and splitting paths where they leave and re-enter.
+// Clipped closed paths become open paths
+func (drawing *Drawing) Clip(rect FRect) {
+	var out []Path
+	inside := func(p FPoint) bool {
+		return p.X >= rect.Min.X && p.X <= rect.Max.X && p.Y >= rect.Min.Y && p.Y <= rect.Max.Y
+	}
+	for _, pa := range drawing.Paths {
+		n := len(pa.Points)
+		if n == 1 {
+			if inside(pa.Points[0]) {
+				out = append(out, pa)
+			}
+			continue
+		}
+		allInside := true
+		for _, p := range pa.Points {
+			allInside = allInside && inside(p)
+		}
+		if allInside {
+			out = append(out, pa)
+			continue
+		}
+		var cur *Path
+		segments := n - 1
+		if pa.Closed {
+			segments = n
+		}
+		for i := 0; i < segments; i++ {
+			p0 := pa.Points[i]
+			p1 := pa.Points[(i+1)%n]
+			c0, c1, ok := clipSegment(p0, p1, rect)
+			if !ok {
+				cur = nil
+				continue
+			}
+			if cur == nil || cur.Points[len(cur.Points)-1] != c0 {
+				out = append(out, Path{Points: []FPoint{c0}, Color: pa.Color, Width: pa.Width})
+				cur = &out[len(out)-1]
+			}
+			cur.Points = append(cur.Points, c1)
+			if c1 != p1 { // left the rect
+				cur = nil
+			}
+		}
+	}
+	drawing.Paths = out
+}
+
+// Viewport returns a copy of the drawing clipped to rect and scaled so rect fits the unit square from (0,0) to (1,1), centered and keeping its aspect,
+// leaving the drawing unchanged. ScaleTo the output size, or use ViewportTo, to draw it
+func (drawing *Drawing) Viewport(rect FRect) *Drawing {
+	return drawing.ViewportTo(rect, FRect{Max: FPoint{X: 1, Y: 1}})
+}
+
+// ViewportTo is Viewport scaling rect to fit out rather than the unit square.
+// The mapping depends only on rect and out, not on what is visible, so drawing the viewports of a shrinking rect with DrawToImage into the same out zooms smoothly.
+// out with Min.Y above Max.Y flips y, eg FRect{Min: FPoint{Y: h}, Max: FPoint{X: w}} for a y down image of size w x h
+func (drawing *Drawing) ViewportTo(rect FRect, out FRect) *Drawing {
+	v := drawing.Clone()
+	v.Clip(rect)
+	rw, rh := rect.Max.X-rect.Min.X, rect.Max.Y-rect.Min.Y
+	ow, oh := out.Max.X-out.Min.X, out.Max.Y-out.Min.Y
+	scale := math.Min(math.Abs(ow)/rw, math.Abs(oh)/rh)
+	if math.IsInf(scale, 0) || math.IsNaN(scale) { // an empty rect has no size to scale
+		scale = 1
+	}
+	sx, sy := math.Copysign(scale, ow), math.Copysign(scale, oh)
+	rc := FPoint{X: (rect.Min.X + rect.Max.X) / 2, Y: (rect.Min.Y + rect.Max.Y) / 2}
+	oc := FPoint{X: (out.Min.X + out.Max.X) / 2, Y: (out.Min.Y + out.Max.Y) / 2}
+	for i := range v.Paths {
+		for j, p := range v.Paths[i].Points {
+			v.Paths[i].Points[j] = FPoint{X: oc.X + (p.X-rc.X)*sx, Y: oc.Y + (p.Y-rc.Y)*sy}
+		}
+	}
+	return v
+}
+
+// BoxCountingDimension estimates the box counting (Minkowski–Bouligand) dimension of the drawing's lines: for each scale it lays a grid of square
+// boxes scale times the longer side of Bounds, counts the boxes a segment passes through, and returns the least squares slope of log(count) against log(1/scale).
+// nil scales uses 1/4 to 1/256 by halves. Scales should be well inside (0, 1) and spread over a few halvings: large boxes see only the outline,
+// boxes near the segment length see straight lines and give 1, so a curve needs enough iterations that its segments are much shorter than the smallest box.
+// Segments are sampled at a quarter box, which can miss a corner clipped by a line, so it is an estimate, good to about a tenth on the classic curves.
+// Returns NaN for an empty or single point drawing, or fewer than 2 usable scales
+func (drawing *Drawing) BoxCountingDimension(scales []float64) float64 {
+	if scales == nil {
+		scales = []float64{1.0 / 4, 1.0 / 8, 1.0 / 16, 1.0 / 32, 1.0 / 64, 1.0 / 128, 1.0 / 256}
+	}
+	b := drawing.Bounds()
+	extent := math.Max(b.Max.X-b.Min.X, b.Max.Y-b.Min.Y)
+	if extent == 0 {
+		return math.NaN()
+	}
+	var sx, sy, sxx, sxy, n float64
+	for _, scale := range scales {
+		if !(scale > 0) {
+			continue
+		}
+		box := scale * extent
+		cells := make(map[[2]int64]struct{})
+		mark := func(p FPoint) {
+			cells[[2]int64{int64(math.Floor((p.X - b.Min.X) / box)), int64(math.Floor((p.Y - b.Min.Y) / box))}] = struct{}{}
+		}
+		for i := range drawing.Paths {
+			p := &drawing.Paths[i]
+			if len(p.Points) == 1 {
+				mark(p.Points[0])
+			}
+			for j := 0; j < p.Segments(); j++ {
+				p0, p1, _ := p.segment(j)
+				steps := math.Ceil(Length(p0, p1) / (box / 4))
+				for k := 0.0; k <= steps; k++ {
+					f := k / math.Max(steps, 1)
+					mark(FPoint{X: p0.X + f*(p1.X-p0.X), Y: p0.Y + f*(p1.Y-p0.Y)})
+				}
+			}
+		}
+		x, y := math.Log(1/scale), math.Log(float64(len(cells)))
+		sx, sy, sxx, sxy, n = sx+x, sy+y, sxx+x*x, sxy+x*y, n+1
+	}
+	d := n*sxx - sx*sx
+	if n < 2 || d == 0 {
+		return math.NaN()
+	}
+	return (n*sxy - sx*sy) / d
+}
@@ -0,0 +1,249 @@
+package drawing
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestHitTest(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorRED)
+	drw.LineTo(FPoint{X: 10, Y: 0})
+	drw.MoveTo(FPoint{X: 5, Y: -5}, ColorBLUE)
+	drw.LineTo(FPoint{X: 5, Y: 5})
+	cases := []struct {
+		pt   FPoint
+		want int
+		ok   bool
+	}{
+		{FPoint{X: 2, Y: 0.5}, 0, true},
+		{FPoint{X: 5, Y: 0}, 1, true}, // both paths, last drawn wins
+		{FPoint{X: 5.5, Y: 4}, 1, true},
+		{FPoint{X: 11.5, Y: 0}, -1, false}, // past the segment end
+		{FPoint{X: 2, Y: 3}, -1, false},
+	}
+	for _, c := range cases {
+		got, ok := drw.HitTest(c.pt, 1)
+		if got != c.want || ok != c.ok {
+			t.Errorf("HitTest(%v) = %d, %v, want %d, %v", c.pt, got, ok, c.want, c.ok)
+		}
+	}
+
+	tri := Path{Points: []FPoint{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 3}}}
+	mid := FPoint{X: 2, Y: 1.5} // on the closing edge, from (4, 3) back to (0, 0)
+	if tri.ContainsPoint(mid, 0.1) {
+		t.Error("open path contains a point on its missing closing edge")
+	}
+	tri.Closed = true
+	if !tri.ContainsPoint(mid, 0.1) {
+		t.Error("closed path misses a point on its closing edge")
+	}
+}
+
+func TestAspectRatio(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: -1, Y: 0}, ColorBLACK)
+	drw.LineTo(FPoint{X: 3, Y: 0})
+	if r := drw.AspectRatio(); !math.IsInf(r, 1) {
+		t.Errorf("flat drawing AspectRatio = %v, want +Inf", r)
+	}
+	drw.LineTo(FPoint{X: 3, Y: 2})
+	if r := drw.AspectRatio(); r != 2 {
+		t.Errorf("AspectRatio = %v, want 2", r)
+	}
+}
+
+func TestStats(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorBLACK)
+	drw.LineTo(FPoint{X: 3, Y: 4})
+	drw.MoveTo(FPoint{X: 3, Y: 0}, ColorBLACK) // 4 travel from {3 4}
+	drw.LineTo(FPoint{X: 4, Y: 0})
+	drw.LineTo(FPoint{X: 4, Y: 1})
+	drw.Paths[1].Closed = true // closes with sqrt(2)
+	st := drw.Stats()
+	want := DrawingStats{Paths: 2, Points: 5, DrawLength: 7 + math.Sqrt2, TravelLength: 4, Bounds: FRect{Max: FPoint{X: 4, Y: 4}}}
+	if st != want {
+		t.Errorf("Stats = %+v, want %+v", st, want)
+	}
+	if drw.TotalLength() != st.DrawLength || drw.TravelLength() != st.TravelLength || drw.Bounds() != st.Bounds {
+		t.Error("Stats disagrees with TotalLength, TravelLength or Bounds")
+	}
+
+	// an empty path, as Clip can leave, is skipped by the travel and drawing
+	drw.Paths = []Path{{}, drw.Paths[0], {Color: ColorRED}, drw.Paths[1]}
+	want.Paths = 4
+	if st := drw.Stats(); st != want || drw.TravelLength() != want.TravelLength {
+		t.Errorf("with empty paths Stats = %+v and TravelLength %v, want %+v", st, drw.TravelLength(), want)
+	}
+	drw.DrawToImage(image.NewRGBA(image.Rect(0, 0, 8, 8)))
+}
+
+func TestClip(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: -5, Y: 1}, ColorRED) // crosses the rect, leaves and comes back
+	drw.LineTo(FPoint{X: 5, Y: 1})
+	drw.LineTo(FPoint{X: 15, Y: 1})
+	drw.LineTo(FPoint{X: 15, Y: 3})
+	drw.LineTo(FPoint{X: 5, Y: 3})
+	drw.MoveTo(FPoint{X: 20, Y: 20}, ColorBLUE) // outside
+	drw.LineTo(FPoint{X: 30, Y: 20})
+	rect := FRect{Min: FPoint{X: 0, Y: 0}, Max: FPoint{X: 10, Y: 10}}
+	drw.Clip(rect)
+	want := [][]FPoint{
+		{{X: 0, Y: 1}, {X: 5, Y: 1}, {X: 10, Y: 1}},
+		{{X: 10, Y: 3}, {X: 5, Y: 3}},
+	}
+	if len(drw.Paths) != len(want) {
+		t.Fatalf("got %v, want %v", drw.Paths, want)
+	}
+	for i, pts := range want {
+		if len(drw.Paths[i].Points) != len(pts) || drw.Paths[i].Color != ColorRED {
+			t.Fatalf("path %d = %v, want %v", i, drw.Paths[i], pts)
+		}
+		for j := range pts {
+			if drw.Paths[i].Points[j] != pts[j] {
+				t.Errorf("path %d point %d = %v, want %v", i, j, drw.Paths[i].Points[j], pts[j])
+			}
+		}
+	}
+}
+
+func TestViewport(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: -1, Y: 1}, ColorRED) // clipped at x = 0
+	drw.LineTo(FPoint{X: 1, Y: 1})
+	drw.LineTo(FPoint{X: 4, Y: 2})
+	orig := drw.Clone()
+	v := drw.Viewport(FRect{Max: FPoint{X: 4, Y: 2}})
+	if !drw.Equal(orig, 0) {
+		t.Error("Viewport changed the drawing")
+	}
+	// the 4 x 2 rect scales by 1/4 to fill the unit square's width, its center (2,1) moving to (0.5,0.5)
+	want := []FPoint{{X: 0, Y: 0.5}, {X: 0.25, Y: 0.5}, {X: 1, Y: 0.75}}
+	if len(v.Paths) != 1 || len(v.Paths[0].Points) != len(want) {
+		t.Fatalf("Viewport = %v, want %v", v.Paths, want)
+	}
+	for j, p := range v.Paths[0].Points {
+		if math.Abs(p.X-want[j].X) > 1e-9 || math.Abs(p.Y-want[j].Y) > 1e-9 {
+			t.Errorf("point %d = %v, want %v", j, p, want[j])
+		}
+	}
+}
+
+func TestViewportZoom(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 1, Y: 1}, ColorBLACK)
+	drw.LineTo(FPoint{X: 2, Y: 1.5})
+	out := FRect{Min: FPoint{Y: 200}, Max: FPoint{X: 400}} // 400 x 200, y down
+	outer := drw.ViewportTo(FRect{Min: FPoint{X: -4, Y: -4}, Max: FPoint{X: 4, Y: 4}}, out)
+	inner := drw.ViewportTo(FRect{Min: FPoint{X: -2, Y: -2}, Max: FPoint{X: 2, Y: 2}}, out)
+	// both rects share a center, so halving the rect doubles every offset from the output center, the empty space inside the rects included
+	center := FPoint{X: 200, Y: 100}
+	for j, p := range drw.Paths[0].Points {
+		o, i := outer.Paths[0].Points[j], inner.Paths[0].Points[j]
+		wantO := FPoint{X: center.X + p.X*25, Y: center.Y - p.Y*25}
+		wantI := FPoint{X: center.X + p.X*50, Y: center.Y - p.Y*50}
+		if math.Abs(o.X-wantO.X) > 1e-9 || math.Abs(o.Y-wantO.Y) > 1e-9 || math.Abs(i.X-wantI.X) > 1e-9 || math.Abs(i.Y-wantI.Y) > 1e-9 {
+			t.Errorf("point %d maps to %v and %v, want %v and %v", j, o, i, wantO, wantI)
+		}
+	}
+}
+
+func TestSegmentLengthAngle(t *testing.T) {
+	p := Path{Points: []FPoint{{X: 0, Y: 0}, {X: 3, Y: 4}, {X: 3, Y: 0}}, Closed: true}
+	if n := p.Segments(); n != 3 {
+		t.Fatalf("Segments = %d, want 3", n)
+	}
+	if l := p.SegmentLength(0); l != 5 {
+		t.Errorf("SegmentLength(0) = %v, want 5", l)
+	}
+	if a := p.SegmentAngle(1); a != 270 {
+		t.Errorf("SegmentAngle(1) = %v, want 270", a)
+	}
+	if l, a := p.SegmentLength(2), p.SegmentAngle(2); l != 3 || a != 180 {
+		t.Errorf("closing segment = %v, %v, want 3, 180", l, a)
+	}
+	for _, i := range []int{-1, 3} {
+		if !math.IsNaN(p.SegmentLength(i)) || !math.IsNaN(p.SegmentAngle(i)) {
+			t.Errorf("segment %d out of range is not NaN", i)
+		}
+	}
+	p.Closed = false
+	if !math.IsNaN(p.SegmentLength(2)) {
+		t.Error("open path has a closing segment")
+	}
+}
+
+func TestSegmentLengthStats(t *testing.T) {
+	var drw Drawing
+	if lo, hi, mean := drw.SegmentLengthStats(); lo != 0 || hi != 0 || mean != 0 {
+		t.Errorf("empty drawing stats %v %v %v", lo, hi, mean)
+	}
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorBLACK)
+	drw.LineTo(FPoint{X: 0, Y: 0})
+	if lo, hi, mean := drw.SegmentLengthStats(); lo != 0 || hi != 0 || mean != 0 {
+		t.Errorf("all zero stats %v %v %v", lo, hi, mean)
+	}
+	drw.LineTo(FPoint{X: 3, Y: 4})
+	drw.MoveTo(FPoint{X: 10, Y: 0}, ColorBLACK)
+	drw.LineTo(FPoint{X: 10, Y: 1})
+	if lo, hi, mean := drw.SegmentLengthStats(); lo != 1 || hi != 5 || mean != 2 {
+		t.Errorf("stats %v %v %v, want 1 5 2", lo, hi, mean)
+	}
+}
+
+func koch(drw *Drawing, p0, p1 FPoint, level int) {
+	if level == 0 {
+		drw.LineTo(p1)
+		return
+	}
+	dx, dy := (p1.X-p0.X)/3, (p1.Y-p0.Y)/3
+	a := FPoint{X: p0.X + dx, Y: p0.Y + dy}
+	b := FPoint{X: p0.X + 2*dx, Y: p0.Y + 2*dy}
+	peak := FPoint{X: a.X + dx/2 - dy*math.Sqrt(3)/2, Y: a.Y + dy/2 + dx*math.Sqrt(3)/2}
+	koch(drw, p0, a, level-1)
+	koch(drw, a, peak, level-1)
+	koch(drw, peak, b, level-1)
+	koch(drw, b, p1, level-1)
+}
+
+func TestBoxCountingDimension(t *testing.T) {
+	var line Drawing
+	line.MoveTo(FPoint{X: 0, Y: 0}, ColorBLACK)
+	line.LineTo(FPoint{X: 100, Y: 30})
+
+	var fill Drawing // rows closer than the smallest box fill the square
+	for y := 0.0; y <= 100; y += 0.1 {
+		fill.MoveTo(FPoint{X: 0, Y: y}, ColorBLACK)
+		fill.LineTo(FPoint{X: 100, Y: y})
+	}
+
+	var curve Drawing
+	curve.MoveTo(FPoint{X: 0, Y: 0}, ColorBLACK)
+	koch(&curve, FPoint{X: 0, Y: 0}, FPoint{X: 729, Y: 0}, 6)
+
+	cases := []struct {
+		name string
+		drw  *Drawing
+		want float64
+	}{
+		{"line", &line, 1},
+		{"fill", &fill, 2},
+		{"koch", &curve, math.Log(4) / math.Log(3)},
+	}
+	for _, c := range cases {
+		if got := c.drw.BoxCountingDimension(nil); math.Abs(got-c.want) > 0.1 {
+			t.Errorf("%s: dimension %v, want about %v", c.name, got, c.want)
+		}
+	}
+
+	var empty Drawing
+	if got := empty.BoxCountingDimension(nil); !math.IsNaN(got) {
+		t.Errorf("empty drawing dimension %v, want NaN", got)
+	}
+	if got := line.BoxCountingDimension([]float64{0.1}); !math.IsNaN(got) {
+		t.Errorf("one scale dimension %v, want NaN", got)
+	}
+}
@@ -0,0 +1,176 @@
+package drawing
+
+import (
+	"math"
+	"sort"
+)
+
+// AlphaShape returns a concave hull of all points of the drawing, counterclockwise from the lowest point, for masking and packing.
+// It is the k nearest neighbours concave hull (Moreira and Santos) with k = alpha rounded, at least 3: from the lowest point it walks to whichever
+// of the k points nearest the current point turns most to the right without crossing the hull so far, until it is back at the start.
+// If no candidate is left or a point ends up outside, it starts over with k+1. Small alpha, around 3, follows concavities closely,
+// alpha at or above the point count gives the convex hull.
+// Limitations: the hull is a single polygon, so separate clusters are joined, holes are not found, and every step sorts the
+// remaining points by distance and every retry starts again, so the cost grows at least with the square of the point count,
+// which is slow on the tens of thousands of points of a deep fractal (Subdivide or sample the drawing first)
+func (drawing *Drawing) AlphaShape(alpha float64) []FPoint {
+	pts := uniquePoints(drawing)
+	k := int(math.Round(alpha))
+	if k < 3 {
+		k = 3
+	}
+	for ; k < len(pts)-1; k++ {
+		if hull, ok := knnHull(pts, k); ok {
+			return hull
+		}
+	}
+	return convexHull(pts)
+}
+
+// knnHull returns the k nearest neighbours concave hull of pts, not ok if the walk gets stuck or leaves a point outside
+func knnHull(pts []FPoint, k int) ([]FPoint, bool) {
+	first := 0
+	for i, pt := range pts {
+		if pt.Y < pts[first].Y || (pt.Y == pts[first].Y && pt.X < pts[first].X) {
+			first = i
+		}
+	}
+	start := pts[first]
+	rest := make([]FPoint, 0, len(pts)-1)
+	rest = append(rest, pts[:first]...)
+	rest = append(rest, pts[first+1:]...)
+	hull := []FPoint{start}
+	cur, back := start, math.Pi // back is the direction from cur to the previous point, at the start a virtual point to the left
+	for step := 0; ; step++ {
+		if step == 3 {
+			rest = append(rest, start) // the start can close the hull once it is a triangle
+		}
+		sort.SliceStable(rest, func(i, j int) bool { return Length(cur, rest[i]) < Length(cur, rest[j]) })
+		n := k
+		if n > len(rest) {
+			n = len(rest)
+		}
+		cands := append([]FPoint(nil), rest[:n]...)
+		// the smallest counterclockwise turn from back is the largest right hand turn
+		turn := func(p FPoint) float64 {
+			t := math.Mod(math.Atan2(p.Y-cur.Y, p.X-cur.X)-back+4*math.Pi, 2*math.Pi)
+			if t == 0 {
+				t = 2 * math.Pi // going straight back is the last resort
+			}
+			return t
+		}
+		sort.SliceStable(cands, func(i, j int) bool { return turn(cands[i]) < turn(cands[j]) })
+		next, found := FPoint{}, false
+		for _, c := range cands {
+			crosses := false
+			for i := 0; i+1 < len(hull)-1 && !crosses; i++ { // the last hull edge ends at cur
+				crosses = segmentsCross(cur, c, hull[i], hull[i+1])
+			}
+			if !crosses {
+				next, found = c, true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+		if next == start {
+			break
+		}
+		for i, pt := range rest {
+			if pt == next {
+				rest = append(rest[:i], rest[i+1:]...)
+				break
+			}
+		}
+		hull = append(hull, next)
+		back = math.Atan2(cur.Y-next.Y, cur.X-next.X)
+		cur = next
+	}
+	for _, pt := range pts {
+		if !insidePolygon(hull, pt) {
+			return nil, false
+		}
+	}
+	return hull, true
+}
+
+// insidePolygon reports whether pt is inside or on the closed polygon poly
+func insidePolygon(poly []FPoint, pt FPoint) bool {
+	in := false
+	for i := range poly {
+		a, b := poly[i], poly[(i+1)%len(poly)]
+		if SegmentDistance(pt, a, b) < 1e-9 {
+			return true
+		}
+		if (a.Y > pt.Y) != (b.Y > pt.Y) && pt.X < a.X+(pt.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y) {
+			in = !in
+		}
+	}
+	return in
+}
+
+// uniquePoints returns the distinct points of all paths of drawing, rounded to 9 decimals so float drift does not split a point in two
+func uniquePoints(drawing *Drawing) []FPoint {
+	seen := make(map[FPoint]bool)
+	var pts []FPoint
+	for _, pa := range drawing.Paths {
+		for _, pt := range pa.Points {
+			pt = FPoint{X: math.Round(pt.X*1e9) / 1e9, Y: math.Round(pt.Y*1e9) / 1e9}
+			if !seen[pt] {
+				seen[pt] = true
+				pts = append(pts, pt)
+			}
+		}
+	}
+	return pts
+}
+
+// segmentsCross reports whether segments a0->a1 and b0->b1 intersect anywhere but a shared end point
+func segmentsCross(a0, a1, b0, b1 FPoint) bool {
+	if a0 == b0 || a0 == b1 || a1 == b0 || a1 == b1 {
+		return false
+	}
+	d1, d2 := cross(b0, b1, a0), cross(b0, b1, a1)
+	d3, d4 := cross(a0, a1, b0), cross(a0, a1, b1)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	// collinear overlaps
+	return (d1 == 0 && onSegment(b0, b1, a0)) || (d2 == 0 && onSegment(b0, b1, a1)) ||
+		(d3 == 0 && onSegment(a0, a1, b0)) || (d4 == 0 && onSegment(a0, a1, b1))
+}
+
+// cross returns the z of (b-a)x(c-a), positive if c is left of a->b
+func cross(a, b, c FPoint) float64 {
+	return (b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)
+}
+
+// onSegment reports whether c, collinear with a->b, lies within it
+func onSegment(a, b, c FPoint) bool {
+	return math.Min(a.X, b.X) <= c.X && c.X <= math.Max(a.X, b.X) && math.Min(a.Y, b.Y) <= c.Y && c.Y <= math.Max(a.Y, b.Y)
+}
+
+// convexHull returns the convex hull of pts counterclockwise from the lowest point, Andrew's monotone chain
+func convexHull(pts []FPoint) []FPoint {
+	ps := append([]FPoint(nil), pts...)
+	sort.Slice(ps, func(i, j int) bool { return ps[i].Y < ps[j].Y || (ps[i].Y == ps[j].Y && ps[i].X < ps[j].X) })
+	if len(ps) < 3 {
+		return ps
+	}
+	var hull []FPoint
+	for pass := 0; pass < 2; pass++ { // the chain right of the lowest to highest line, then its left
+		base := len(hull)
+		for _, p := range ps {
+			for len(hull) >= base+2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+				hull = hull[:len(hull)-1]
+			}
+			hull = append(hull, p)
+		}
+		hull = hull[:len(hull)-1] // each chain ends where the other starts
+		for i, j := 0, len(ps)-1; i < j; i, j = i+1, j-1 {
+			ps[i], ps[j] = ps[j], ps[i]
+		}
+	}
+	return hull
+}
@@ -0,0 +1,53 @@
+package drawing
+
+import "testing"
+
+// uShape is a U of points 1 apart, walls two points thick around an opening 3 wide, whose convex hull is its bounding box
+func uShape() *Drawing {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 0, Y: 4}, ColorBLACK)
+	for y := 3.0; y >= 0; y-- {
+		drw.LineTo(FPoint{X: 0, Y: y})
+	}
+	for x := 1.0; x <= 5; x++ {
+		drw.LineTo(FPoint{X: x, Y: 0})
+	}
+	for y := 1.0; y <= 4; y++ {
+		drw.LineTo(FPoint{X: 5, Y: y})
+	}
+	for _, pt := range []FPoint{{X: 4, Y: 4}, {X: 4, Y: 3}, {X: 4, Y: 2}, {X: 4, Y: 1}, {X: 3, Y: 1}, {X: 2, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 2}, {X: 1, Y: 3}, {X: 1, Y: 4}} {
+		drw.LineTo(pt)
+	}
+	return &drw
+}
+
+func TestAlphaShape(t *testing.T) {
+	drw := uShape()
+	hull := drw.AlphaShape(3)
+	if len(hull) < 8 {
+		t.Fatalf("AlphaShape = %v, too few points for a U", hull)
+	}
+	if hull[0] != (FPoint{X: 0, Y: 0}) {
+		t.Errorf("hull starts at %v, want the lowest point", hull[0])
+	}
+	if insidePolygon(hull, FPoint{X: 2.5, Y: 3}) {
+		t.Errorf("the inside of the U is inside the hull %v", hull)
+	}
+	for _, pa := range drw.Paths {
+		for _, pt := range pa.Points {
+			if !insidePolygon(hull, pt) {
+				t.Errorf("%v outside the hull", pt)
+			}
+		}
+	}
+	convex := drw.AlphaShape(100)
+	want := []FPoint{{X: 0, Y: 0}, {X: 5, Y: 0}, {X: 5, Y: 4}, {X: 0, Y: 4}}
+	if len(convex) != len(want) {
+		t.Fatalf("AlphaShape(100) = %v, want %v", convex, want)
+	}
+	for i := range want {
+		if convex[i] != want[i] {
+			t.Errorf("convex hull point %d = %v, want %v", i, convex[i], want[i])
+		}
+	}
+}
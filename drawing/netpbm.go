@@ -0,0 +1,111 @@
+package drawing
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+)
+
+// pnmLineMax is the longest line the plain (ASCII) netpbm formats allow
+const pnmLineMax = 70
+
+// WritePBM writes the drawing centered in rect as a raw (P4) 1-bit portable bitmap, every drawn pixel black on white. The drawing is unchanged
+func (drawing *Drawing) WritePBM(w io.Writer, rect image.Rectangle) error {
+	return drawing.writePBM(w, rect, true)
+}
+
+// WritePlainPBM is WritePBM in the plain (P1) ASCII format
+func (drawing *Drawing) WritePlainPBM(w io.Writer, rect image.Rectangle) error {
+	return drawing.writePBM(w, rect, false)
+}
+
+// writePBM writes a P4 bitmap if binary, otherwise P1
+func (drawing *Drawing) writePBM(w io.Writer, rect image.Rectangle, binary bool) error {
+	img := drawing.Clone().rasterize(&rect)
+	b := img.Bounds()
+	bw := bufio.NewWriter(w)
+	magic := "P1"
+	if binary {
+		magic = "P4"
+	}
+	fmt.Fprintf(bw, "%s\n%d %d\n", magic, b.Dx(), b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		var bits byte
+		col := 0
+		for x := b.Min.X; x < b.Max.X; x++ {
+			black := img.RGBAAt(x, y).A != 0
+			if binary {
+				if black {
+					bits |= 0x80 >> uint(col%8)
+				}
+				if col%8 == 7 || x == b.Max.X-1 { // rows are padded to a whole byte
+					bw.WriteByte(bits)
+					bits = 0
+				}
+			} else {
+				if col > 0 && col%pnmLineMax == 0 {
+					bw.WriteByte('\n')
+				}
+				if black {
+					bw.WriteByte('1')
+				} else {
+					bw.WriteByte('0')
+				}
+			}
+			col++
+		}
+		if !binary {
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+// WritePPM writes the drawing centered in rect as a raw (P6) color portable pixmap over a white background, 8 bits per channel. The drawing is unchanged
+func (drawing *Drawing) WritePPM(w io.Writer, rect image.Rectangle) error {
+	return drawing.writePPM(w, rect, true)
+}
+
+// WritePlainPPM is WritePPM in the plain (P3) ASCII format
+func (drawing *Drawing) WritePlainPPM(w io.Writer, rect image.Rectangle) error {
+	return drawing.writePPM(w, rect, false)
+}
+
+// writePPM writes a P6 pixmap if binary, otherwise P3
+func (drawing *Drawing) writePPM(w io.Writer, rect image.Rectangle, binary bool) error {
+	img := onWhite(drawing.Clone().rasterize(&rect))
+	b := img.Bounds()
+	bw := bufio.NewWriter(w)
+	magic := "P3"
+	if binary {
+		magic = "P6"
+	}
+	fmt.Fprintf(bw, "%s\n%d %d\n255\n", magic, b.Dx(), b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		line := 0
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			if binary {
+				bw.Write([]byte{c.R, c.G, c.B})
+				continue
+			}
+			for _, v := range []uint8{c.R, c.G, c.B} {
+				s := fmt.Sprint(v)
+				if line > 0 && line+1+len(s) > pnmLineMax {
+					bw.WriteByte('\n')
+					line = 0
+				} else if line > 0 {
+					bw.WriteByte(' ')
+					line++
+				}
+				bw.WriteString(s)
+				line += len(s)
+			}
+		}
+		if !binary {
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
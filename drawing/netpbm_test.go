@@ -0,0 +1,121 @@
+package drawing
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"testing"
+)
+
+func pnmDrawing() *Drawing {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorRED)
+	drw.LineTo(FPoint{X: 10, Y: 10})
+	return &drw
+}
+
+// readPlain reads the header and count values of a plain netpbm file, bitmaps have one digit per value
+func readPlain(t *testing.T, data []byte, magic string, header, count int, bitmap bool) []int {
+	r := bufio.NewReader(bytes.NewReader(data))
+	var m string
+	fmt.Fscan(r, &m)
+	if m != magic {
+		t.Fatalf("magic %q, want %q", m, magic)
+	}
+	for i := 0; i < header; i++ {
+		var v int
+		fmt.Fscan(r, &v)
+	}
+	vals := make([]int, 0, count)
+	for len(vals) < count {
+		if bitmap {
+			c, err := r.ReadByte()
+			if err != nil {
+				t.Fatalf("%d of %d values: %v", len(vals), count, err)
+			}
+			if c == '0' || c == '1' {
+				vals = append(vals, int(c-'0'))
+			}
+			continue
+		}
+		var v int
+		if _, err := fmt.Fscan(r, &v); err != nil {
+			t.Fatalf("%d of %d values: %v", len(vals), count, err)
+		}
+		vals = append(vals, v)
+	}
+	return vals
+}
+
+func TestWritePBM(t *testing.T) {
+	rect := image.Rect(0, 0, 12, 5)
+	var plain, raw bytes.Buffer
+	drw := pnmDrawing()
+	if err := drw.WritePlainPBM(&plain, rect); err != nil {
+		t.Fatal(err)
+	}
+	if !drw.Equal(pnmDrawing(), 0) {
+		t.Error("WritePlainPBM changed the drawing")
+	}
+	if err := drw.WritePBM(&raw, rect); err != nil {
+		t.Fatal(err)
+	}
+	if !drw.Equal(pnmDrawing(), 0) {
+		t.Error("WritePBM changed the drawing")
+	}
+	const header = "P4\n12 5\n"
+	if !bytes.HasPrefix(raw.Bytes(), []byte(header)) || raw.Len() != len(header)+5*2 {
+		t.Fatalf("P4 is %d bytes: %q", raw.Len(), raw.Bytes())
+	}
+	bits := readPlain(t, plain.Bytes(), "P1", 2, 12*5, true)
+	black := 0
+	for i, b := range bits {
+		rawBit := raw.Bytes()[len(header)+i/12*2+i%12/8] >> uint(7-i%12%8) & 1
+		if int(rawBit) != b {
+			t.Fatalf("pixel %d: P1 %d, P4 %d", i, b, rawBit)
+		}
+		black += b
+	}
+	if black == 0 || black == len(bits) {
+		t.Errorf("%d of %d pixels black", black, len(bits))
+	}
+}
+
+func TestWritePPM(t *testing.T) {
+	rect := image.Rect(0, 0, 30, 4)
+	var plain, raw bytes.Buffer
+	drw := pnmDrawing()
+	if err := drw.WritePlainPPM(&plain, rect); err != nil {
+		t.Fatal(err)
+	}
+	if err := drw.WritePPM(&raw, rect); err != nil {
+		t.Fatal(err)
+	}
+	if !drw.Equal(pnmDrawing(), 0) {
+		t.Error("writing a PPM changed the drawing")
+	}
+	for _, line := range bytes.Split(plain.Bytes(), []byte("\n")) {
+		if len(line) > pnmLineMax {
+			t.Fatalf("P3 line of %d characters", len(line))
+		}
+	}
+	const header = "P6\n30 4\n255\n"
+	if !bytes.HasPrefix(raw.Bytes(), []byte(header)) {
+		t.Fatalf("P6 header: %q", raw.Bytes()[:len(header)])
+	}
+	pix := raw.Bytes()[len(header):]
+	vals := readPlain(t, plain.Bytes(), "P3", 3, 30*4*3, false)
+	red := false
+	for i, v := range vals {
+		if int(pix[i]) != v {
+			t.Fatalf("value %d: P3 %d, P6 %d", i, v, pix[i])
+		}
+		if i%3 == 0 && v == 255 && vals[i+1] == 0 && vals[i+2] == 0 {
+			red = true
+		}
+	}
+	if !red {
+		t.Error("no red pixel drawn")
+	}
+}
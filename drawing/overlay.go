@@ -0,0 +1,97 @@
+package drawing
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+)
+
+// BlendMode is how a Layer combines with the layers below it
+type BlendMode int
+
+const (
+	// BlendNormal paints the layer over what is below, the usual source over compositing, so the top layer wins where both draw
+	BlendNormal BlendMode = iota
+	// BlendAdditive adds the layer to what is below channel by channel, clamped at full, so crossings brighten, red and blue make magenta
+	BlendAdditive
+	// BlendMultiply multiplies the layer with what is below where both draw, so crossings darken, the layer shows unchanged over nothing
+	BlendMultiply
+)
+
+// Layer is one drawing of a RenderOverlay
+type Layer struct {
+	Drawing *Drawing   // nil draws nothing
+	Color   color.RGBA // color of all paths of the layer, or their own colors if Color.A is 0
+	Mode    BlendMode
+}
+
+// RenderOverlay renders layers, first at the bottom, as a png to w of size rect, over a transparent background.
+// All layers are flipped and centered together so they stay registered, and each is drawn on its own then blended down with its Mode.
+// The layer drawings are not changed
+func RenderOverlay(w io.Writer, layers []Layer, rect image.Rectangle) error {
+	var all Drawing
+	ends := make([]int, len(layers))
+	for i, l := range layers {
+		if l.Drawing != nil {
+			for _, pa := range l.Drawing.Clone().Paths {
+				if l.Color.A != 0 {
+					pa.Color = l.Color
+				}
+				all.Paths = append(all.Paths, pa)
+			}
+		}
+		ends[i] = len(all.Paths)
+	}
+	all.Flip(true)
+	all.CenterWithMargin(RectBounds(rect), FPoint{X: 0.1, Y: 0.1}) //add a 10% of size margin
+	dst := image.NewRGBA(rect)
+	src := image.NewRGBA(rect)
+	start := 0
+	for i, l := range layers {
+		draw.Draw(src, src.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		all.DrawRangeToImage(src, start, ends[i])
+		blendInto(dst, src, l.Mode)
+		start = ends[i]
+	}
+	return png.Encode(w, dst)
+}
+
+// blendInto blends src into dst with mode, both alpha premultiplied and of the same bounds
+func blendInto(dst, src *image.RGBA, mode BlendMode) {
+	for i := 0; i < len(src.Pix); i += 4 {
+		if src.Pix[i+3] == 0 { // nothing drawn here, every mode keeps dst
+			continue
+		}
+		s := color.RGBA{src.Pix[i], src.Pix[i+1], src.Pix[i+2], src.Pix[i+3]}
+		d := color.RGBA{dst.Pix[i], dst.Pix[i+1], dst.Pix[i+2], dst.Pix[i+3]}
+		b := blend(mode, s, d)
+		dst.Pix[i], dst.Pix[i+1], dst.Pix[i+2], dst.Pix[i+3] = b.R, b.G, b.B, b.A
+	}
+}
+
+// blend returns src blended over dst with mode, both alpha premultiplied.
+// Multiply is the W3C separable blend, premultiplied: s*(1-da) + d*(1-sa) + s*d, with over alpha
+func blend(mode BlendMode, s, d color.RGBA) color.RGBA {
+	sa, da := float64(s.A)/255, float64(d.A)/255
+	ch := func(sc, dc uint8) uint8 {
+		sv, dv := float64(sc)/255, float64(dc)/255
+		var v float64
+		switch mode {
+		case BlendAdditive:
+			v = math.Min(sv+dv, 1)
+		case BlendMultiply:
+			v = sv*(1-da) + dv*(1-sa) + sv*dv
+		default:
+			v = sv + dv*(1-sa)
+		}
+		return uint8(v*255 + 0.5)
+	}
+	a := uint8((sa+da-sa*da)*255 + 0.5)
+	if mode == BlendAdditive {
+		a = ch(s.A, d.A)
+	}
+	return color.RGBA{ch(s.R, d.R), ch(s.G, d.G), ch(s.B, d.B), a}
+}
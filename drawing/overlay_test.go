@@ -0,0 +1,88 @@
+package drawing
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestBlend(t *testing.T) {
+	half := color.RGBA{0, 0, 128, 128} // half transparent blue, premultiplied
+	cases := []struct {
+		mode BlendMode
+		s, d color.RGBA
+		want color.RGBA
+	}{
+		{BlendNormal, ColorRED, ColorBLUE, ColorRED},
+		{BlendNormal, half, ColorRED, color.RGBA{127, 0, 128, 255}},
+		{BlendAdditive, ColorRED, ColorBLUE, color.RGBA{255, 0, 255, 255}},
+		{BlendAdditive, half, color.RGBA{}, half},               // over nothing alpha adds to the layer's own
+		{BlendAdditive, half, half, color.RGBA{0, 0, 255, 255}}, // and clamps at full
+		{BlendMultiply, ColorRED, ColorBLUE, color.RGBA{0, 0, 0, 255}},
+		{BlendMultiply, ColorRED, ColorWHITE, ColorRED},
+		{BlendMultiply, ColorRED, color.RGBA{}, ColorRED},
+	}
+	for _, c := range cases {
+		if got := blend(c.mode, c.s, c.d); got != c.want {
+			t.Errorf("blend(%v, %v, %v) = %v, want %v", c.mode, c.s, c.d, got, c.want)
+		}
+	}
+}
+
+func TestRenderOverlay(t *testing.T) {
+	var h, v Drawing
+	h.MoveTo(FPoint{X: 0, Y: 5}, ColorBLACK)
+	h.LineTo(FPoint{X: 10, Y: 5})
+	v.MoveTo(FPoint{X: 5, Y: 0}, ColorBLUE)
+	v.LineTo(FPoint{X: 5, Y: 10})
+	layers := []Layer{{Drawing: &h, Color: ColorRED}, {Drawing: &v, Mode: BlendAdditive}}
+	var buf bytes.Buffer
+	if err := RenderOverlay(&buf, layers, image.Rect(0, 0, 50, 50)); err != nil {
+		t.Fatal(err)
+	}
+	if h.Paths[0].Points[1] != (FPoint{X: 10, Y: 5}) || h.Paths[0].Color != ColorBLACK {
+		t.Error("RenderOverlay changed a layer drawing")
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[color.RGBA]int{}
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			counts[color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)]++
+		}
+	}
+	if counts[ColorRED] == 0 || counts[ColorBLUE] == 0 || counts[color.RGBA{255, 0, 255, 255}] != 1 {
+		t.Errorf("pixel colors %v, want red, blue and one magenta crossing", counts)
+	}
+}
+
+func TestRenderOverlayAdditiveOverEmpty(t *testing.T) {
+	var v Drawing
+	v.MoveTo(FPoint{X: 5, Y: 0}, ColorBLUE)
+	v.LineTo(FPoint{X: 5, Y: 10})
+	half := color.RGBA{0, 0, 128, 128}
+	layers := []Layer{{}, {Drawing: &v, Color: half, Mode: BlendAdditive}} // the nil layer draws nothing
+	var buf bytes.Buffer
+	if err := RenderOverlay(&buf, layers, image.Rect(0, 0, 20, 20)); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[color.RGBA]int{}
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			counts[color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)]++
+		}
+	}
+	if counts[half] == 0 || counts[half]+counts[color.RGBA{}] != 400 {
+		t.Errorf("pixel colors %v, want the half transparent layer color over transparent", counts)
+	}
+}
@@ -0,0 +1,128 @@
+package drawing
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBoxDownscale(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	src.SetRGBA(0, 0, ColorRED) // one of four samples in output pixel (0,0)
+	src.SetRGBA(2, 0, ColorBLUE)
+	src.SetRGBA(3, 0, ColorBLUE)
+	src.SetRGBA(2, 1, ColorBLUE)
+	src.SetRGBA(3, 1, ColorBLUE)
+	dst := BoxDownscale(src, 2)
+	if dst.Bounds() != image.Rect(0, 0, 2, 1) {
+		t.Fatalf("bounds %v", dst.Bounds())
+	}
+	if got := dst.RGBAAt(0, 0); got.R != 64 || got.A != 64 {
+		t.Errorf("quarter covered pixel = %v, want R=64 A=64", got)
+	}
+	if got := dst.RGBAAt(1, 0); got != ColorBLUE {
+		t.Errorf("fully covered pixel = %v, want %v", got, ColorBLUE)
+	}
+}
+
+func TestRenderPngSupersample(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorBLACK)
+	drw.LineTo(FPoint{X: 10, Y: 3})
+	rect := image.Rect(0, 0, 50, 40)
+	path := filepath.Join(t.TempDir(), "ss.png")
+	_, err := drw.RenderPngWithOptions(&rect, path, &PngOptions{Supersample: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds() != rect {
+		t.Fatalf("bounds %v, want %v", img.Bounds(), rect)
+	}
+	partial := 0
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 50; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a > 0 && a < 0xffff {
+				partial++
+			}
+		}
+	}
+	if partial == 0 {
+		t.Error("no partially covered pixels, line is not anti-aliased")
+	}
+}
+
+func TestEncodePngDPI(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	var buf bytes.Buffer
+	if err := EncodePngDPI(&buf, img, 300); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	chunk := b[pngHeaderLen : pngHeaderLen+4+4+9+4]
+	if string(chunk[4:8]) != "pHYs" {
+		t.Fatalf("chunk after IHDR is %q", chunk[4:8])
+	}
+	if ppm := binary.BigEndian.Uint32(chunk[8:]); ppm != 11811 || binary.BigEndian.Uint32(chunk[12:]) != ppm || chunk[16] != 1 {
+		t.Errorf("pHYs %v, want 11811 pixels per meter both ways", chunk[8:17])
+	}
+	if crc := binary.BigEndian.Uint32(chunk[17:]); crc != crc32.ChecksumIEEE(chunk[4:17]) {
+		t.Errorf("pHYs crc %x is wrong", crc)
+	}
+	if _, err := png.Decode(&buf); err != nil {
+		t.Errorf("png with pHYs does not decode: %v", err)
+	}
+}
+
+func TestRenderPlotterPreview(t *testing.T) {
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorBLACK)
+	drw.LineTo(FPoint{X: 0, Y: 10})
+	drw.MoveTo(FPoint{X: 100, Y: 0}, ColorBLACK)
+	drw.LineTo(FPoint{X: 100, Y: 10})
+	rect := image.Rect(0, 0, 200, 100)
+	path := filepath.Join(t.TempDir(), "preview.png")
+	if err := drw.RenderPlotterPreview(&rect, path, ColorRED); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the travel runs from the end of the first line to the start of the second, across the middle in dashes
+	red, gaps := 0, 0
+	for x := 30; x < 170; x++ {
+		hit := false
+		for y := 0; y < 100; y++ {
+			if r, g, b, a := img.At(x, y).RGBA(); r == 0xffff && g == 0 && b == 0 && a == 0xffff {
+				hit = true
+			}
+		}
+		if hit {
+			red++
+		} else {
+			gaps++
+		}
+	}
+	if red == 0 || gaps == 0 {
+		t.Errorf("travel columns: %d dashed, %d gaps, want both", red, gaps)
+	}
+}
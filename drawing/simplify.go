@@ -0,0 +1,64 @@
+package drawing
+
+import "math"
+
+// SimplifyAdaptive removes points with a Douglas-Peucker simplification whose tolerance follows the local curvature.
+// The turn at a point is the change in heading between its two segments, 0 to 180 degrees, and a point is kept
+// if it is farther from the simplified span than baseTol*2/(1+3*turn/180): twice baseTol on straight runs,
+// half of it at a full reversal. Sharp corners, the detail of a fractal, survive while gentle bends are merged.
+// Path end points are always kept and paths of fewer than 3 points are unchanged
+func (drawing *Drawing) SimplifyAdaptive(baseTol float64) {
+	for i := range drawing.Paths {
+		p := &drawing.Paths[i]
+		if len(p.Points) < 3 {
+			continue
+		}
+		keep := make([]bool, len(p.Points))
+		keep[0], keep[len(p.Points)-1] = true, true
+		p.simplifyAdaptive(0, len(p.Points)-1, baseTol, keep)
+		pts := p.Points[:0]
+		for j, k := range keep {
+			if k {
+				pts = append(pts, p.Points[j])
+			}
+		}
+		p.Points = pts
+	}
+}
+
+// simplifyAdaptive marks in keep the points between lo and hi that SimplifyAdaptive keeps
+func (p *Path) simplifyAdaptive(lo, hi int, baseTol float64, keep []bool) {
+	if hi-lo < 2 {
+		return
+	}
+	worst, worstExcess := -1, 0.0
+	for k := lo + 1; k < hi; k++ {
+		excess := SegmentDistance(p.Points[k], p.Points[lo], p.Points[hi]) - adaptiveTolerance(p.turn(k), baseTol)
+		if excess > worstExcess {
+			worst, worstExcess = k, excess
+		}
+	}
+	if worst < 0 {
+		return
+	}
+	keep[worst] = true
+	p.simplifyAdaptive(lo, worst, baseTol, keep)
+	p.simplifyAdaptive(worst, hi, baseTol, keep)
+}
+
+// turn returns the change in heading at point k, between segments k-1 and k, from 0 to 180 degrees
+func (p *Path) turn(k int) float64 {
+	d := math.Abs(p.SegmentAngle(k) - p.SegmentAngle(k-1))
+	if d > 180 {
+		d = 360 - d
+	}
+	if math.IsNaN(d) { // a zero length segment has no heading to turn from
+		d = 0
+	}
+	return d
+}
+
+// adaptiveTolerance scales baseTol by turn, see SimplifyAdaptive
+func adaptiveTolerance(turn, baseTol float64) float64 {
+	return baseTol * 2 / (1 + 3*turn/180)
+}
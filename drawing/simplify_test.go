@@ -0,0 +1,28 @@
+package drawing
+
+import "testing"
+
+func TestSimplifyAdaptive(t *testing.T) {
+	var drw Drawing
+	// a straight run, a gentle bump and a sharp spike, both 0.8 to 0.9 off the line
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorBLACK)
+	for _, pt := range []FPoint{{X: 1, Y: 0}, {X: 2, Y: 0}, {X: 7, Y: 0.8}, {X: 12, Y: 0}, {X: 12.5, Y: 0.9}, {X: 13, Y: 0}} {
+		drw.LineTo(pt)
+	}
+	drw.MoveTo(FPoint{X: 0, Y: 1}, ColorBLACK)
+	drw.LineTo(FPoint{X: 1, Y: 1})
+	drw.SimplifyAdaptive(1)
+	want := []FPoint{{X: 0, Y: 0}, {X: 12.5, Y: 0.9}, {X: 13, Y: 0}} // the spike survives, the bump and the run do not
+	got := drw.Paths[0].Points
+	if len(got) != len(want) {
+		t.Fatalf("SimplifyAdaptive kept %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("point %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if len(drw.Paths[1].Points) != 2 {
+		t.Errorf("two point path changed: %v", drw.Paths[1].Points)
+	}
+}
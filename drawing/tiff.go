@@ -0,0 +1,250 @@
+package drawing
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/image/tiff"
+)
+
+// RenderTiff renders a drawing centered as an RGBA tiff with given filepath and given size (rect), a lossless alternative to png for very large images.
+// compression is "none" (or ""), "lzw" or "deflate". golang.org/x/image/tiff can only decode lzw, so lzw is written by EncodeTiffLZW.
+// If rect is nil size defaults to 2kx2k
+func (drawing *Drawing) RenderTiff(rect *image.Rectangle, filePath string, compression string) error {
+	var opt tiff.Options
+	lzw := false
+	switch strings.ToLower(compression) {
+	case "", "none":
+		opt.Compression = tiff.Uncompressed
+	case "lzw":
+		lzw = true
+	case "deflate":
+		opt.Compression = tiff.Deflate
+	default:
+		return fmt.Errorf("unknown tiff compression %q, use none, lzw or deflate", compression)
+	}
+	img := drawing.rasterize(rect)
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if lzw {
+		err = EncodeTiffLZW(f, img)
+	} else {
+		err = tiff.Encode(f, img, &opt)
+	}
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// RenderTiffCMYK renders a drawing centered as an uncompressed CMYK tiff with given filepath and given size (rect), for print.
+// The drawing is rendered over white then each pixel is converted with color.CMYKModel, the naive conversion
+// K = 1 - max(R, G, B), C = (1 - R - K) / (1 - K) and so on, with no ICC profile, so check colors against the printer's proof.
+// If rect is nil size defaults to 2kx2k
+func (drawing *Drawing) RenderTiffCMYK(rect *image.Rectangle, filePath string) error {
+	img := drawing.rasterize(rect)
+	cmyk := image.NewCMYK(img.Bounds())
+	draw.Draw(cmyk, cmyk.Bounds(), image.NewUniform(color.CMYK{0, 0, 0, 0}), image.Point{}, draw.Src) // white
+	draw.Draw(cmyk, cmyk.Bounds(), img, img.Bounds().Min, draw.Over)
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	err = EncodeTiffCMYK(f, cmyk)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// tiff tag field types
+const (
+	tiffShort    = 3
+	tiffLong     = 4
+	tiffRational = 5
+)
+
+type tiffEntry struct {
+	tag, typ uint16
+	count    uint32
+	value    uint32
+}
+
+// EncodeTiffCMYK writes img as a baseline, single strip, uncompressed little endian CMYK tiff
+func EncodeTiffCMYK(w io.Writer, img *image.CMYK) error {
+	b := img.Bounds()
+	rows := make([][]byte, b.Dy())
+	for y := range rows {
+		i := img.PixOffset(b.Min.X, b.Min.Y+y)
+		rows[y] = img.Pix[i : i+b.Dx()*4]
+	}
+	return writeTiff(w, b, rows,
+		tiffEntry{259, tiffShort, 1, 1}, // Compression none
+		tiffEntry{262, tiffShort, 1, 5}, // PhotometricInterpretation separated
+		tiffEntry{332, tiffShort, 1, 1}, // InkSet CMYK
+	)
+}
+
+// EncodeTiffLZW writes img as a single strip, lzw compressed little endian RGBA tiff with associated (premultiplied) alpha
+func EncodeTiffLZW(w io.Writer, img *image.RGBA) error {
+	b := img.Bounds()
+	var strip bytes.Buffer
+	lw := newTiffLZW(&strip)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		i := img.PixOffset(b.Min.X, y)
+		lw.write(img.Pix[i : i+b.Dx()*4])
+	}
+	lw.close()
+	return writeTiff(w, b, [][]byte{strip.Bytes()},
+		tiffEntry{259, tiffShort, 1, 5}, // Compression lzw
+		tiffEntry{262, tiffShort, 1, 2}, // PhotometricInterpretation RGB
+		tiffEntry{338, tiffShort, 1, 1}, // ExtraSamples associated alpha
+	)
+}
+
+// writeTiff writes a single strip little endian tiff of 4 8 bit samples per pixel, the strip being the concatenated chunks,
+// with the tags every tiff here shares and the format specific entries
+func writeTiff(w io.Writer, b image.Rectangle, strip [][]byte, entries ...tiffEntry) error {
+	width, height := uint32(b.Dx()), uint32(b.Dy())
+	var stripLen uint32
+	for _, chunk := range strip {
+		stripLen += uint32(len(chunk))
+	}
+	// layout: header, strip, bits per sample, x and y resolution, then the IFD
+	extra := 8 + stripLen + stripLen%2
+	ifd := extra + 8 + 8 + 8
+	entries = append(entries,
+		tiffEntry{256, tiffLong, 1, width},          // ImageWidth
+		tiffEntry{257, tiffLong, 1, height},         // ImageLength
+		tiffEntry{258, tiffShort, 4, extra},         // BitsPerSample 8,8,8,8
+		tiffEntry{273, tiffLong, 1, 8},              // StripOffsets
+		tiffEntry{277, tiffShort, 1, 4},             // SamplesPerPixel
+		tiffEntry{278, tiffLong, 1, height},         // RowsPerStrip
+		tiffEntry{279, tiffLong, 1, stripLen},       // StripByteCounts
+		tiffEntry{282, tiffRational, 1, extra + 8},  // XResolution
+		tiffEntry{283, tiffRational, 1, extra + 16}, // YResolution
+		tiffEntry{284, tiffShort, 1, 1},             // PlanarConfiguration chunky
+		tiffEntry{296, tiffShort, 1, 2},             // ResolutionUnit inch
+	)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].tag < entries[j].tag }) // IFD entries must be in tag order
+
+	bw := bufio.NewWriter(w)
+	le := binary.LittleEndian
+	bw.WriteString("II*\x00")
+	binary.Write(bw, le, ifd)
+	for _, chunk := range strip {
+		bw.Write(chunk)
+	}
+	if stripLen%2 == 1 {
+		bw.WriteByte(0) // tiff offsets must be even
+	}
+	binary.Write(bw, le, [4]uint16{8, 8, 8, 8})
+	binary.Write(bw, le, [4]uint32{72, 1, 72, 1})
+	binary.Write(bw, le, uint16(len(entries)))
+	for _, e := range entries {
+		binary.Write(bw, le, e.tag)
+		binary.Write(bw, le, e.typ)
+		binary.Write(bw, le, e.count)
+		binary.Write(bw, le, e.value) // a single short is left justified, which little endian gives for free
+	}
+	binary.Write(bw, le, uint32(0)) // no next IFD
+	return bw.Flush()
+}
+
+// tiff lzw codes, 256 literals then clear and end of information, with codes of 9 to 12 bits
+const (
+	lzwClear    = 256
+	lzwEOI      = 257
+	lzwFirst    = 258
+	lzwMaxWidth = 12
+)
+
+// tiffLZW is a tiff lzw encoder. Codes are packed most significant bit first and, unlike gif's lzw in compress/lzw,
+// the code width grows one code early, as tiff decoders expect
+type tiffLZW struct {
+	w      *bytes.Buffer
+	bits   uint32
+	nBits  uint
+	width  uint
+	next   int
+	table  map[int]int // prefix code << 8 | byte to code
+	prefix int         // code of the bytes read but not yet written, -1 for none
+}
+
+func newTiffLZW(w *bytes.Buffer) *tiffLZW {
+	e := &tiffLZW{w: w, width: 9, prefix: -1}
+	e.reset()
+	return e
+}
+
+// reset writes a clear code and empties the table
+func (e *tiffLZW) reset() {
+	e.emit(lzwClear)
+	e.width = 9
+	e.next = lzwFirst
+	e.table = make(map[int]int)
+}
+
+// emit writes code at the current width
+func (e *tiffLZW) emit(code int) {
+	e.bits |= uint32(code) << (32 - e.width - e.nBits)
+	e.nBits += e.width
+	for e.nBits >= 8 {
+		e.w.WriteByte(byte(e.bits >> 24))
+		e.bits <<= 8
+		e.nBits -= 8
+	}
+}
+
+// emitPrefix writes the pending prefix code and counts the table entry the decoder makes for it,
+// growing the width early, or clearing the table before it would pass 12 bit codes
+func (e *tiffLZW) emitPrefix() {
+	e.emit(e.prefix)
+	e.next++
+	if e.next == 1<<lzwMaxWidth-2 {
+		e.reset()
+	} else if e.next >= 1<<e.width {
+		e.width++
+	}
+}
+
+func (e *tiffLZW) write(p []byte) {
+	for _, c := range p {
+		if e.prefix < 0 {
+			e.prefix = int(c)
+			continue
+		}
+		key := e.prefix<<8 | int(c)
+		if code, ok := e.table[key]; ok {
+			e.prefix = code
+			continue
+		}
+		e.table[key] = e.next
+		e.emitPrefix()
+		e.prefix = int(c)
+	}
+}
+
+// close writes the last prefix and the end of information code, then pads to a byte
+func (e *tiffLZW) close() {
+	if e.prefix >= 0 {
+		e.emitPrefix()
+	}
+	e.emit(lzwEOI)
+	if e.nBits > 0 {
+		e.w.WriteByte(byte(e.bits >> 24))
+	}
+}
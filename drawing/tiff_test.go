@@ -0,0 +1,138 @@
+package drawing
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/tiff"
+)
+
+func TestEncodeTiffCMYK(t *testing.T) {
+	img := image.NewCMYK(image.Rect(0, 0, 3, 2))
+	img.Set(1, 1, color.CMYK{1, 2, 3, 4})
+	var buf bytes.Buffer
+	err := EncodeTiffCMYK(&buf, img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	le := binary.LittleEndian
+	if string(data[:4]) != "II*\x00" {
+		t.Fatalf("bad header %q", data[:4])
+	}
+	if got := data[8+(1*3+1)*4 : 8+(1*3+1)*4+4]; !bytes.Equal(got, []byte{1, 2, 3, 4}) {
+		t.Errorf("pixel (1,1) = %v, want [1 2 3 4]", got)
+	}
+	ifd := le.Uint32(data[4:])
+	n := int(le.Uint16(data[ifd:]))
+	tags := map[uint16]uint32{}
+	for i := 0; i < n; i++ {
+		e := data[int(ifd)+2+i*12:]
+		tags[le.Uint16(e)] = le.Uint32(e[8:])
+	}
+	want := map[uint16]uint32{256: 3, 257: 2, 262: 5, 277: 4, 279: 24, 332: 1}
+	for tag, v := range want {
+		if tags[tag] != v {
+			t.Errorf("tag %d = %d, want %d", tag, tags[tag], v)
+		}
+	}
+}
+
+func TestRenderTiff(t *testing.T) {
+	rect := image.Rect(0, 0, 40, 30)
+	for _, compression := range []string{"none", "LZW", "Deflate"} {
+		var drw Drawing
+		drw.MoveTo(FPoint{X: 0, Y: 0}, ColorRED)
+		drw.LineTo(FPoint{X: 2, Y: 1})
+		path := filepath.Join(t.TempDir(), "t.tiff")
+		err := drw.RenderTiff(&rect, path, compression)
+		if err != nil {
+			t.Fatalf("%s: %v", compression, err)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := tiff.Decode(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", compression, err)
+		}
+		if img.Bounds() != rect {
+			t.Errorf("%s: bounds %v, want %v", compression, img.Bounds(), rect)
+		}
+	}
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorRED)
+	drw.LineTo(FPoint{X: 2, Y: 1})
+	if err := drw.RenderTiff(&rect, filepath.Join(t.TempDir(), "t.tiff"), "zip"); err == nil {
+		t.Error("zip: expected error")
+	}
+}
+
+func TestEncodeTiffLZW(t *testing.T) {
+	// noise fills the code table so it grows to 12 bit codes and clears, the flat half has long runs
+	img := image.NewRGBA(image.Rect(0, 0, 160, 120))
+	rnd := rand.New(rand.NewSource(1))
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 160; x++ {
+			img.Set(x, y, color.RGBA{uint8(rnd.Intn(256)), uint8(rnd.Intn(4)), 0, 255})
+		}
+	}
+	for _, r := range []image.Rectangle{{}, image.Rect(0, 0, 1, 1), image.Rect(20, 10, 140, 100)} {
+		sub := img.SubImage(r).(*image.RGBA)
+		var buf bytes.Buffer
+		err := EncodeTiffLZW(&buf, sub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Empty() {
+			continue // a tiff must have pixels to decode
+		}
+		got, err := tiff.Decode(&buf)
+		if err != nil {
+			t.Fatalf("%v: %v", r, err)
+		}
+		if got.Bounds().Size() != r.Size() {
+			t.Fatalf("%v: size %v, want %v", r, got.Bounds().Size(), r.Size())
+		}
+		for y := 0; y < r.Dy(); y++ {
+			for x := 0; x < r.Dx(); x++ {
+				if g, w := color.RGBAModel.Convert(got.At(x, y)), sub.At(r.Min.X+x, r.Min.Y+y); g != w {
+					t.Fatalf("%v: pixel (%d,%d) = %v, want %v", r, x, y, g, w)
+				}
+			}
+		}
+	}
+}
+
+func TestRenderTiffCMYK(t *testing.T) {
+	rect := image.Rect(0, 0, 40, 30)
+	var drw Drawing
+	drw.MoveTo(FPoint{X: 0, Y: 0}, ColorRED) // flipped and centered, this passes through pixel (33, 7)
+	drw.LineTo(FPoint{X: 2, Y: 1})
+	path := filepath.Join(t.TempDir(), "t.tiff")
+	if err := drw.RenderTiffCMYK(&rect, path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pixel := func(x, y int) color.CMYK {
+		i := 8 + (y*rect.Dx()+x)*4
+		return color.CMYK{C: data[i], M: data[i+1], Y: data[i+2], K: data[i+3]}
+	}
+	if got, want := pixel(33, 7), (color.CMYK{C: 0, M: 255, Y: 255, K: 0}); got != want {
+		t.Errorf("red pixel = %v, want %v", got, want)
+	}
+	if got := pixel(0, 0); got != (color.CMYK{}) {
+		t.Errorf("background pixel = %v, want white %v", got, color.CMYK{})
+	}
+}
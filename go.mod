@@ -2,4 +2,7 @@ module github.com/exyzzy/lsys
 
 go 1.19
 
-require github.com/StephaneBunel/bresenham v0.0.0-20211027152503-ec76d7b8e923
+require (
+	github.com/StephaneBunel/bresenham v0.0.0-20211027152503-ec76d7b8e923
+	golang.org/x/image v0.24.0
+)
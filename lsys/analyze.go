@@ -0,0 +1,203 @@
+package lsys
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/exyzzy/lsys/drawing"
+)
+
+// maxEstimatedSymbols is the generated size above which Validate warns that a fractal will be slow or run out of memory
+const maxEstimatedSymbols = 1e8
+
+// isVariable reports whether r is rewritten by rules, as opposed to a turn/stack command or space that LSys copies unchanged
+func isVariable(r rune) bool {
+	switch r {
+	case '-', '+', '[', ']', ' ':
+		return false
+	}
+	return true
+}
+
+// BracketDepth returns the '[' ']' nesting depth at the end of s and the lowest depth reached, for checking fragments before joining them.
+// A balanced string ends at 0 with min 0, a negative min is a ']' with nothing to pop (that DrawLSys ignores)
+func BracketDepth(s string) (finalDepth, minDepth int) {
+	for _, v := range s {
+		switch v {
+		case '[':
+			finalDepth++
+		case ']':
+			finalDepth--
+			if finalDepth < minDepth {
+				minDepth = finalDepth
+			}
+		}
+	}
+	return
+}
+
+// AnalyzeRules returns the expansion factor of each rule symbol: the number of variables its rule output contains.
+// A factor of 0 erases the symbol, 1 keeps the count unchanged (a fixed point when the output is the symbol itself), above 1 grows each level
+func AnalyzeRules(rules map[string]string) map[string]float64 {
+	factors := make(map[string]float64, len(rules))
+	for k, r := range rules {
+		n := 0
+		for _, v := range r {
+			if isVariable(v) {
+				n++
+			}
+		}
+		factors[k] = float64(n)
+	}
+	return factors
+}
+
+// variableCount returns the number of variables in the generated string at level, without generating it
+func variableCount(axiom string, rules map[string]string, level int) (total float64) {
+	counts := make(map[rune]float64)
+	for _, v := range axiom {
+		if isVariable(v) {
+			counts[v]++
+		}
+	}
+	for i := 0; i < level; i++ {
+		next := make(map[rune]float64)
+		for sym, n := range counts {
+			for _, v := range rules[string(sym)] {
+				if isVariable(v) {
+					next[v] += n
+				}
+			}
+		}
+		counts = next
+	}
+	for _, n := range counts {
+		total += n
+	}
+	return
+}
+
+// axioms returns the candidate axioms, Axiom alone when Axioms is empty
+func (f LFractal) axioms() []string {
+	if len(f.Axioms) == 0 {
+		return []string{f.Axiom}
+	}
+	axioms := make([]string, len(f.Axioms))
+	for i, a := range f.Axioms {
+		axioms[i] = a.Axiom
+	}
+	return axioms
+}
+
+// Validate checks that every variable in the axioms and rule outputs has a rule, that axiom weights are valid and that Levels is not negative, returning an error if not.
+// It also returns warnings from AnalyzeRules for rules that erase or never change their symbol, fractals that do not grow, and fractals that grow too large
+func (f LFractal) Validate() (warnings []string, err error) {
+	if f.Levels < 0 {
+		return nil, fmt.Errorf("%s: negative levels: %d", f.Name, f.Levels)
+	}
+	check := func(where, s string) error {
+		for _, v := range s {
+			if _, ok := f.Rules[string(v)]; isVariable(v) && !ok && !f.Turtle.isCommand(v) {
+				return fmt.Errorf("%s: no rule for: %s in %s", f.Name, string(v), where)
+			}
+		}
+		return nil
+	}
+	err = f.validateWeights()
+	if err != nil {
+		return nil, err
+	}
+	for _, axiom := range f.axioms() {
+		err = check("axiom", axiom)
+		if err != nil {
+			return nil, err
+		}
+	}
+	keys := make([]string, 0, len(f.Rules))
+	for k := range f.Rules {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		err = check("rule "+k, f.Rules[k])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	factors := AnalyzeRules(f.Rules)
+	maxFactor := 0.0
+	for _, k := range keys {
+		switch {
+		case factors[k] == 0 && k != "F" && k != "f":
+			warnings = append(warnings, fmt.Sprintf("%s: rule %s produces no variables, %s stops expanding after one level", f.Name, k, k))
+		case f.Rules[k] == k && k != "F" && k != "f": // F->F and f->f are the usual way to keep the turtle commands
+			warnings = append(warnings, fmt.Sprintf("%s: rule %s is a fixed point, %s never changes", f.Name, k, k))
+		}
+		maxFactor = math.Max(maxFactor, factors[k])
+	}
+	if len(keys) > 0 && maxFactor <= 1 {
+		warnings = append(warnings, fmt.Sprintf("%s: no rule expands to more than one variable, the fractal does not grow with level", f.Name))
+	}
+	estimate := 0.0
+	for _, axiom := range f.axioms() {
+		estimate = math.Max(estimate, variableCount(axiom, f.Rules, f.Levels))
+	}
+	if estimate > maxEstimatedSymbols {
+		warnings = append(warnings, fmt.Sprintf("%s: %.3g variables at level %d, rendering may be slow or run out of memory", f.Name, estimate, f.Levels))
+	}
+	return warnings, nil
+}
+
+// LintFractal returns advisory warnings for common authoring mistakes, without rendering:
+// symbols with no rule that are not turtle commands (usually typos), rules that expand to nothing,
+// rules never used by the axiom or any rule, unbalanced brackets in the axiom or a rule, and turn angles outside (0, 180] degrees
+func LintFractal(f LFractal) []string {
+	var warnings []string
+	keys := make([]string, 0, len(f.Rules))
+	for k := range f.Rules {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	used := make(map[string]bool)
+	missing := make(map[string]bool)
+	scan := func(where, s string) {
+		if final, low := BracketDepth(s); final != 0 || low < 0 {
+			warnings = append(warnings, fmt.Sprintf("%s: unbalanced brackets in %s, depth ends at %d and reaches %d", f.Name, where, final, low))
+		}
+		for _, v := range s {
+			if !isVariable(v) {
+				continue
+			}
+			used[string(v)] = true
+			if _, ok := f.Rules[string(v)]; !ok && !missing[string(v)] && !f.Turtle.isCommand(v) {
+				missing[string(v)] = true
+				warnings = append(warnings, fmt.Sprintf("%s: %s in %s has no rule and is not a turtle command", f.Name, string(v), where))
+			}
+		}
+	}
+	for _, axiom := range f.axioms() {
+		scan("axiom", axiom)
+	}
+	for _, k := range keys {
+		scan("rule "+k, f.Rules[k])
+	}
+	for _, k := range keys {
+		if f.Rules[k] == "" {
+			warnings = append(warnings, fmt.Sprintf("%s: rule %s expands to nothing, %s disappears after one level", f.Name, k, k))
+		}
+		if !used[k] {
+			warnings = append(warnings, fmt.Sprintf("%s: rule %s is never used", f.Name, k))
+		}
+	}
+	angle := f.Angle
+	if f.Turtle != nil && f.Turtle.AngleUnit == Radians {
+		angle = drawing.ToDegrees(angle)
+	}
+	if angle <= 0 || angle > 180 {
+		warnings = append(warnings, fmt.Sprintf("%s: turn angle %v is outside the usual (0, 180] degrees", f.Name, angle))
+	}
+	return warnings
+}
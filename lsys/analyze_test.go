@@ -0,0 +1,123 @@
+package lsys
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeRules(t *testing.T) {
+	factors := AnalyzeRules(map[string]string{"X": "X+YF+", "Y": "-FX-Y", "F": "F", "E": "+-"})
+	want := map[string]float64{"X": 3, "Y": 3, "F": 1, "E": 0}
+	for k, v := range want {
+		if factors[k] != v {
+			t.Errorf("factor %s = %v, want %v", k, factors[k], v)
+		}
+	}
+}
+
+func TestValidateLibrary(t *testing.T) {
+	for _, f := range fractals {
+		warnings, err := f.Validate()
+		if err != nil {
+			t.Errorf("%s: %v", f.Name, err)
+		}
+		for _, w := range warnings {
+			t.Logf("warning: %s", w)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	_, err := LFractal{Name: "bad", Axiom: "FX", Rules: map[string]string{"F": "F"}}.Validate()
+	if err == nil {
+		t.Error("expected error for missing rule X")
+	}
+	_, err = LFractal{Name: "neg", Axiom: "F", Rules: map[string]string{"F": "F"}, Levels: -1}.Validate()
+	if err == nil {
+		t.Error("expected error for negative levels")
+	}
+	warnings, err := LFractal{Name: "still", Axiom: "X", Rules: map[string]string{"X": "X", "F": "F"}, Levels: 3}.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("got warnings %q, want fixed point and no growth", warnings)
+	}
+}
+
+func TestVariableCount(t *testing.T) {
+	f, _ := LsysByName("Koch")
+	s, err := LSys(f.Axiom, f.Rules, f.Levels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := 0
+	for _, v := range s {
+		if isVariable(v) {
+			want++
+		}
+	}
+	if got := variableCount(f.Axiom, f.Rules, f.Levels); got != float64(want) {
+		t.Errorf("variableCount = %v, want %v", got, want)
+	}
+}
+
+func TestLintFractal(t *testing.T) {
+	f := LFractal{Name: "typo", Axiom: "X", Rules: map[string]string{"X": "F+Xq]", "F": "FF", "G": "", "Y": "G"}, Angle: 270}
+	warnings := LintFractal(f)
+	want := []string{
+		"typo: unbalanced brackets in rule X, depth ends at -1 and reaches -1",
+		"typo: q in rule X has no rule and is not a turtle command",
+		"typo: rule G expands to nothing, G disappears after one level",
+		"typo: rule Y is never used",
+		"typo: turn angle 270 is outside the usual (0, 180] degrees",
+	}
+	if len(warnings) != len(want) {
+		t.Fatalf("got %q, want %q", warnings, want)
+	}
+	for i := range want {
+		if warnings[i] != want[i] {
+			t.Errorf("warning %d = %q, want %q", i, warnings[i], want[i])
+		}
+	}
+	koch, _ := LsysByName("Koch")
+	if w := LintFractal(koch); len(w) != 0 {
+		t.Errorf("Koch: unexpected warnings %q", w)
+	}
+	koch.Angle = math.Pi / 3
+	koch.Turtle = &TurtleConfig{AngleUnit: Radians}
+	if w := LintFractal(koch); len(w) != 0 {
+		t.Errorf("Koch in radians: unexpected warnings %q", w)
+	}
+	koch.Angle = 4
+	if w := LintFractal(koch); len(w) != 1 || !strings.Contains(w[0], "turn angle 229.18") {
+		t.Errorf("4 radians gave warnings %q, want one about 229.18 degrees", w)
+	}
+}
+
+func TestBracketDepth(t *testing.T) {
+	cases := []struct {
+		s          string
+		final, min int
+	}{
+		{"", 0, 0},
+		{"F[+F][-F]F", 0, 0},
+		{"[[F]", 1, 0},
+		{"F]]", -2, -2},
+		{"][", 0, -1},
+		{"[F]][[", 1, -1},
+	}
+	for _, c := range cases {
+		final, low := BracketDepth(c.s)
+		if final != c.final || low != c.min {
+			t.Errorf("BracketDepth(%q) = %d, %d, want %d, %d", c.s, final, low, c.final, c.min)
+		}
+	}
+	// a fragment leaving one open joins with one closing it
+	a, _ := BracketDepth("F[+F")
+	b, low := BracketDepth("F]F")
+	if a+b != 0 || a+low < 0 {
+		t.Errorf("fragments do not balance: %d, %d, %d", a, b, low)
+	}
+}
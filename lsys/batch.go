@@ -0,0 +1,168 @@
+package lsys
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/exyzzy/lsys/drawing"
+)
+
+// RenderBatch renders each fractal in black to dir/<Name>.<format> for every format (png, jpg, jpeg, svg, tif, tiff) using workers goroutines.
+// It does not stop on failure, the result holds one error per fractal, nil if all its formats rendered.
+// dir is created if missing, each file is written to a temporary name and renamed into place so readers never see a partial file.
+// workers below 1 uses a single worker
+func RenderBatch(fractals []LFractal, dir string, size image.Point, formats []string, workers int) []error {
+	_, errs := renderBatch(fractals, dir, size, formats, workers)
+	return errs
+}
+
+// ManifestEntry describes one fractal rendered by RenderBatchManifest
+type ManifestEntry struct {
+	Name    string            `json:"name"`
+	Axiom   string            `json:"axiom"`            // the axiom built, picked from Axioms if there are any
+	Axioms  []WeightedAxiom   `json:"axioms,omitempty"` // candidate axioms
+	Seed    int64             `json:"seed"`             // seeds the pick from Axioms
+	Rules   map[string]string `json:"rules"`
+	Levels  int               `json:"levels"`
+	Theta   float64           `json:"theta"`
+	Angle   float64           `json:"angle"`
+	OnePath bool              `json:"onePath"`
+	Turtle  *TurtleConfig     `json:"turtle,omitempty"` // turtle settings, absent for DefaultTurtleConfig
+	Files   []string          `json:"files"`            // file names rendered, relative to the manifest
+	Paths   int               `json:"paths"`            // path count of the built drawing
+	Bounds  drawing.FRect     `json:"bounds"`           // bounds of the built drawing before scaling
+	Error   string            `json:"error,omitempty"`  // why some or all files are missing
+}
+
+// RenderBatchManifest is RenderBatch that also writes dir/manifest.json, a JSON array with a ManifestEntry per fractal, for gallery pages.
+// An error writing the manifest is returned for every fractal
+func RenderBatchManifest(fractals []LFractal, dir string, size image.Point, formats []string, workers int) []error {
+	entries, errs := renderBatch(fractals, dir, size, formats, workers)
+	for i, err := range errs {
+		if err != nil {
+			entries[i].Error = err.Error()
+		}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, "manifest.json"), append(data, '\n'), 0644)
+	}
+	if err != nil {
+		for i := range errs {
+			errs[i] = joinErrors(errs[i], fmt.Errorf("manifest: %w", err))
+		}
+	}
+	return errs
+}
+
+// renderBatch renders as RenderBatch, also returning a manifest entry per fractal
+func renderBatch(fractals []LFractal, dir string, size image.Point, formats []string, workers int) ([]ManifestEntry, []error) {
+	entries := make([]ManifestEntry, len(fractals))
+	errs := make([]error, len(fractals))
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return entries, errs
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				entries[i], errs[i] = renderFormats(fractals[i], dir, size, formats)
+			}
+		}()
+	}
+	for i := range fractals {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return entries, errs
+}
+
+// renderFormats builds fractal once and renders a copy of it to each format, joining the errors
+func renderFormats(fractal LFractal, dir string, size image.Point, formats []string) (ManifestEntry, error) {
+	axiom, _ := fractal.seededAxiom() // an error is reported by BuildLsys
+	entry := ManifestEntry{Name: fractal.Name, Axiom: axiom, Axioms: fractal.Axioms, Seed: fractal.Seed, Rules: fractal.Rules, Levels: fractal.Levels,
+		Theta: fractal.Theta, Angle: fractal.Angle, OnePath: fractal.OnePath, Turtle: fractal.Turtle, Files: []string{}}
+	drw, err := BuildLsys(fractal, drawing.ColorBLACK)
+	if err != nil {
+		return entry, fmt.Errorf("%s: %w", fractal.Name, err)
+	}
+	entry.Paths = len(drw.Paths)
+	entry.Bounds = drw.Bounds()
+	rect := image.Rectangle{Max: size}
+	var errs []error
+	for _, format := range formats {
+		name := fractal.Name + "." + strings.ToLower(format)
+		err = renderFile(drw.Clone(), &rect, filepath.Join(dir, name))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", fractal.Name, err))
+			continue
+		}
+		entry.Files = append(entry.Files, name)
+	}
+	return entry, joinErrors(errs...)
+}
+
+// joinedErrors is the error joinErrors returns, one message per line
+type joinedErrors []error
+
+func (e joinedErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e joinedErrors) Unwrap() []error { return e }
+
+// joinErrors joins the non nil errs as errors.Join does, which needs go 1.20, nil if there are none
+func joinErrors(errs ...error) error {
+	var e joinedErrors
+	for _, err := range errs {
+		if err != nil {
+			e = append(e, err)
+		}
+	}
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// renderFile renders drw to a temporary file beside filePath then renames it to filePath.
+// The file is made 0644 like the other renderers' files, CreateTemp makes it owner only
+func renderFile(drw *drawing.Drawing, rect *image.Rectangle, filePath string) error {
+	dir, base := filepath.Split(filePath)
+	tmp, err := os.CreateTemp(dir, "."+strings.TrimSuffix(base, filepath.Ext(base))+"-*"+filepath.Ext(base))
+	if err != nil {
+		return err
+	}
+	err = tmp.Chmod(0644)
+	tmp.Close()
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	_, err = drw.Render(rect, tmp.Name())
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
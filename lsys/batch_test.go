@@ -0,0 +1,83 @@
+package lsys
+
+import (
+	"encoding/json"
+	"image"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestRenderBatch(t *testing.T) {
+	koch, _ := LsysByName("Koch")
+	koch.Levels = 2
+	dragon, _ := LsysByName("Dragon")
+	dragon.Levels = 4
+	bad := LFractal{Name: "Bad", Axiom: "FQ", Rules: map[string]string{"F": "F"}, Levels: 1}
+	dir := filepath.Join(t.TempDir(), "out")
+	errs := RenderBatch([]LFractal{koch, bad, dragon}, dir, image.Point{X: 64, Y: 64}, []string{"png", "svg"}, 2)
+	if errs[0] != nil || errs[2] != nil {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if errs[1] == nil {
+		t.Error("expected an error for Bad")
+	}
+	for _, name := range []string{"Koch.png", "Koch.svg", "Dragon.png", "Dragon.svg"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if runtime.GOOS != "windows" && info.Mode().Perm() != 0644 {
+			t.Errorf("%s mode %v, want 0644", name, info.Mode().Perm())
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 4 {
+		t.Errorf("got %d files, want 4 with no temporary files left", len(entries))
+	}
+	errs = RenderBatch([]LFractal{koch}, dir, image.Point{X: 64, Y: 64}, []string{"gif"}, 1)
+	if errs[0] == nil {
+		t.Error("expected an error for unsupported format")
+	}
+}
+
+func TestRenderBatchManifest(t *testing.T) {
+	koch, _ := LsysByName("Koch")
+	koch.Levels = 1
+	koch.Axioms = []WeightedAxiom{{Axiom: "F", Weight: 1}, {Axiom: "F++F", Weight: 2}}
+	koch.Seed = 3
+	koch.Turtle = &TurtleConfig{DrawLength: 2, BackRune: 'b'}
+	bad := LFractal{Name: "Bad", Axiom: "FQ", Rules: map[string]string{"F": "F"}, Levels: 1}
+	dir := t.TempDir()
+	errs := RenderBatchManifest([]LFractal{koch, bad}, dir, image.Point{X: 32, Y: 32}, []string{"svg"}, 1)
+	if errs[0] != nil || errs[1] == nil {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []ManifestEntry
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	k := entries[0]
+	if k.Name != "Koch" || len(k.Files) != 1 || k.Files[0] != "Koch.svg" || k.Paths != 1 || k.Bounds.Max.X <= k.Bounds.Min.X || k.Error != "" {
+		t.Errorf("bad Koch entry %+v", k)
+	}
+	if k.Seed != 3 || len(k.Axioms) != 2 || k.Axioms[1] != koch.Axioms[1] || (k.Axiom != "F" && k.Axiom != "F++F") {
+		t.Errorf("Koch entry axioms %q from %v seed %d, want %v seed 3", k.Axiom, k.Axioms, k.Seed, koch.Axioms)
+	}
+	if k.Turtle == nil || *k.Turtle != *koch.Turtle || entries[1].Turtle != nil {
+		t.Errorf("turtles %+v and %+v, want %+v and none", k.Turtle, entries[1].Turtle, koch.Turtle)
+	}
+	if entries[1].Error == "" || len(entries[1].Files) != 0 {
+		t.Errorf("bad Bad entry %+v", entries[1])
+	}
+}
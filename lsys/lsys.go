@@ -6,6 +6,10 @@ import (
 	"image"
 	"image/color"
 	"io"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/exyzzy/lsys/drawing"
@@ -13,68 +17,228 @@ import (
 
 // LSys - axiom: beginning string, rules: rewrite rules, level: number of rewrite iterations
 func LSys(axiom string, rules map[string]string, level int) (result string, err error) {
+	return LSysConfig(axiom, rules, level, nil)
+}
+
+// LSysConfig is LSys that also copies the turtle command symbols of cfg (nil for DefaultTurtleConfig) that have no rule
+func LSysConfig(axiom string, rules map[string]string, level int, cfg *TurtleConfig) (result string, err error) {
+	if level < 0 {
+		err = fmt.Errorf("negative level: %d", level)
+		return
+	}
 	s := axiom
 	for i := 0; i < level; i++ {
-		ns := ""
-		for _, v := range s {
-			switch v {
-			case '-', '+', '[', ']':
-				ns = ns + string(v)
-			case ' ':
-				//ignore space
-			default:
-				r, ok := rules[string(v)]
-				if !ok {
-					err = errors.New("no rule for: " + string(v))
-					return
-				}
-				ns = ns + r
-			}
+		s, err = rewrite(s, rules, cfg)
+		if err != nil {
+			return
 		}
-		s = ns
 	}
 	result = s
 	return
 }
 
-type StackItem struct {
-	Point drawing.FPoint
-	Theta float64
+// LSysStaged rewrites axiom once with each of stages in order, stages[i] at iteration i, so the number of stages is the level,
+// for grammars whose rules change between iterations, such as a final decomposition pass. Each stage must have a rule for every variable it meets
+func LSysStaged(axiom string, stages []map[string]string) (result string, err error) {
+	s := axiom
+	for i, rules := range stages {
+		s, err = rewrite(s, rules, nil)
+		if err != nil {
+			return "", fmt.Errorf("stage %d: %w", i, err)
+		}
+	}
+	return s, nil
 }
 
-// DrawLsys - drw: map point paths to draw into, lSys: the complete lsys string to draw, theta: the beginning angle (orientation), color: the RGBA color to use for all paths, onePath: force a single path for the entire fractal
-func DrawLSys(drw *drawing.Drawing, lSys string, theta float64, angle float64, color color.RGBA, onePath bool) {
-	var stack []StackItem
-	p := drawing.FPoint{X: 0, Y: 0}
-	drw.MoveTo(p, color)
-	for _, v := range lSys {
+// rewrite applies rules to every symbol of s once, see LSysConfig
+func rewrite(s string, rules map[string]string, cfg *TurtleConfig) (result string, err error) {
+	ns := ""
+	for _, v := range s {
 		switch v {
-		case 'F': // draw forward
-			p = drawing.PointFromTheta(p, theta, 1.0)
-			drw.LineTo(p)
-		case '-': // turn left by angle
-			theta -= angle
-		case '+': // turn right by angle
-			theta += angle
-		case 'f': // move forward without drawing
-			p = drawing.PointFromTheta(p, theta, 1.0)
-			if !onePath {
-				drw.MoveTo(p, color)
+		case '-', '+', '[', ']':
+			ns = ns + string(v)
+		case ' ':
+			//ignore space
+		default:
+			r, ok := rules[string(v)]
+			if !ok && cfg.isCommand(v) {
+				r, ok = string(v), true
+			}
+			if !ok {
+				err = errors.New("no rule for: " + string(v))
+				return
 			}
-		case '[': // push current location and direction onto stack
-			var se = StackItem{Point: p, Theta: theta}
-			stack = append(stack, se)
-		case ']': // pop last location and direction from stack
-			n := len(stack) - 1
-			se := stack[n]
-			p = se.Point
-			if !onePath {
-				drw.MoveTo(p, color)
+			ns = ns + r
+		}
+	}
+	result = ns
+	return
+}
+
+// AngleUnit is the unit of LFractal Theta and Angle
+type AngleUnit int
+
+const (
+	Degrees AngleUnit = iota
+	Radians
+)
+
+// TurtleConfig holds the turtle settings used by DrawLSysConfig
+type TurtleConfig struct {
+	DrawLength  float64   // distance moved by 'F' (draw forward), 0 for 1
+	MoveLength  float64   // distance moved by 'f' (move forward without drawing), 0 for 1
+	PenUp       bool      // start with the pen up, so 'F' moves without drawing until a PenDownRune
+	AngleUnit   AngleUnit // unit of theta and angle given to DrawLSys
+	PenUpRune   rune      // lifts the pen without moving, eg 'U', 0 (the default) for none so grammars can use any letter as a variable
+	PenDownRune rune      // lowers the pen without moving, eg 'D', 0 (the default) for none
+	BackRune    rune      // moves DrawLength backward without turning, drawing if the pen is down, eg 'b', 0 (the default) for none
+	// TurnSteps, if above 0, rounds each turn between two draws into a curve of TurnSteps segments, for flowing animation.
+	// The curve is a quadratic Bezier from the middle of the segment before the corner to the middle of the one after,
+	// so the turtle positions, and the branches and pops, are unchanged. Each rounded corner adds TurnSteps points
+	TurnSteps int
+	// OnePathJoined, with OnePath, draws a move without drawing ('f', 'F' with the pen up, or a ']' pop) as a line, for the joined look of a single
+	// unbroken line at the cost of lines the grammar never drew. By default such a move starts a new path when it leaves the end of the path,
+	// and a move that ends where the path ends continues it, so the path count stays as low as the pen allows
+	OnePathJoined bool
+	// BranchJitter, if above 0, gives each branch a turn bias drawn at its '[' from [-BranchJitter, BranchJitter] degrees, whatever AngleUnit,
+	// added to the size of every '+' and '-' turn until its ']', for natural looking trees that vary by branch rather than by step.
+	// The turtle starts with no bias and a ']' restores the bias of the enclosing branch
+	BranchJitter float64
+	BranchSeed   int64 // seeds the BranchJitter biases, the same seed draws the same tree
+	// DrawSymbols are the symbols that draw forward, each like 'F', "" for just "F". A symbol here still needs a rule, such as G->G, to survive rewriting
+	DrawSymbols string
+}
+
+// DefaultTurtleConfig returns the settings used when no TurtleConfig is given, start from it when customizing
+func DefaultTurtleConfig() TurtleConfig {
+	return TurtleConfig{DrawLength: 1.0, MoveLength: 1.0, DrawSymbols: "F"}
+}
+
+// lengths returns DrawLength and MoveLength of c, a 0 length as 1 so the zero TurtleConfig draws like DefaultTurtleConfig
+func (c *TurtleConfig) lengths() (draw, move float64) {
+	draw, move = c.DrawLength, c.MoveLength
+	if draw == 0 {
+		draw = 1
+	}
+	if move == 0 {
+		move = 1
+	}
+	return draw, move
+}
+
+// draws reports whether r is one of the DrawSymbols of c, which must not be nil
+func (c *TurtleConfig) draws(r rune) bool {
+	if c.DrawSymbols == "" {
+		return r == 'F'
+	}
+	return strings.ContainsRune(c.DrawSymbols, r)
+}
+
+// WillRender reports whether DrawLSys would draw anything for the generated string s, with DefaultTurtleConfig
+func WillRender(s string) bool {
+	return WillRenderConfig(s, nil)
+}
+
+// WillRenderConfig is WillRender for cfg, nil for DefaultTurtleConfig: whether s has a draw symbol while the pen is down,
+// following pen commands and the pen state restored by ']', so callers can skip fractals that produce nothing at a level
+func WillRenderConfig(s string, cfg *TurtleConfig) bool {
+	if cfg == nil {
+		def := DefaultTurtleConfig()
+		cfg = &def
+	}
+	pen := !cfg.PenUp
+	var stack []bool
+	for _, v := range s {
+		switch {
+		case cfg.draws(v) || (v != 0 && v == cfg.BackRune):
+			if pen {
+				return true
 			}
-			theta = se.Theta
-			stack = stack[:n]
+		case v == cfg.PenUpRune:
+			pen = false
+		case v == cfg.PenDownRune:
+			pen = true
+		case v == '[':
+			stack = append(stack, pen)
+		case v == ']' && len(stack) > 0:
+			pen = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
 		}
 	}
+	return false
+}
+
+// isCommand reports whether r is one of the configurable turtle commands of c, nil for DefaultTurtleConfig
+func (c *TurtleConfig) isCommand(r rune) bool {
+	if c == nil {
+		def := DefaultTurtleConfig()
+		c = &def
+	}
+	return r != 0 && (r == c.PenUpRune || r == c.PenDownRune || r == c.BackRune)
+}
+
+// DrawLsys - drw: map point paths to draw into, lSys: the complete lsys string to draw, theta: the beginning angle (orientation), color: the RGBA color to use for all paths, onePath: force a single path for the entire fractal
+// Each symbol is fed to a Turtle with DefaultTurtleConfig, a ']' with nothing pushed is ignored
+func DrawLSys(drw *drawing.Drawing, lSys string, theta float64, angle float64, color color.RGBA, onePath bool) {
+	DrawLSysConfig(drw, lSys, theta, angle, color, onePath, nil)
+}
+
+// DrawLSysConfig is DrawLSys with turtle settings cfg, nil for DefaultTurtleConfig.
+// Start cfg from DefaultTurtleConfig, a zero TurtleConfig has the pen up and zero step lengths so it draws nothing
+func DrawLSysConfig(drw *drawing.Drawing, lSys string, theta float64, angle float64, color color.RGBA, onePath bool, cfg *TurtleConfig) {
+	t, angle, cfg := newLSysTurtle(drw, theta, angle, color, onePath, cfg)
+	for _, v := range lSys {
+		drawSymbol(t, v, angle, cfg)
+	}
+}
+
+// DrawLSysStream is DrawLSysConfig reading the symbols from a channel until it is closed, for streaming a fractal into a drawing as it is generated.
+// A Drawing is not safe for concurrent use, so have producer goroutines only send symbols and let DrawLSysStream, in one goroutine,
+// be the only writer to drw. Do not read drw until it returns
+func DrawLSysStream(drw *drawing.Drawing, symbols <-chan rune, theta float64, angle float64, color color.RGBA, onePath bool, cfg *TurtleConfig) {
+	t, angle, cfg := newLSysTurtle(drw, theta, angle, color, onePath, cfg)
+	for v := range symbols {
+		drawSymbol(t, v, angle, cfg)
+	}
+}
+
+// newLSysTurtle returns the turtle DrawLSys draws with, the turn angle in degrees and the settings, defaulting cfg
+func newLSysTurtle(drw *drawing.Drawing, theta float64, angle float64, color color.RGBA, onePath bool, cfg *TurtleConfig) (*Turtle, float64, *TurtleConfig) {
+	if cfg == nil {
+		def := DefaultTurtleConfig()
+		cfg = &def
+	}
+	if cfg.AngleUnit == Radians { // the turtle works in degrees
+		theta = drawing.ToDegrees(theta)
+		angle = drawing.ToDegrees(angle)
+	}
+	return NewTurtle(drw, theta, color, onePath, cfg), angle, cfg
+}
+
+// drawSymbol feeds symbol v to t
+func drawSymbol(t *Turtle, v rune, angle float64, cfg *TurtleConfig) {
+	if cfg.draws(v) { // draw forward, 'F' unless DrawSymbols says otherwise
+		t.Forward()
+		return
+	}
+	switch v {
+	case '-': // turn left by angle
+		t.Turn(-(angle + t.Bias))
+	case '+': // turn right by angle
+		t.Turn(angle + t.Bias)
+	case 'f': // move forward without drawing
+		t.Move()
+	case cfg.PenUpRune: // stop drawing, 'F' now moves
+		t.PenUp()
+	case cfg.PenDownRune: // start drawing again
+		t.PenDown()
+	case cfg.BackRune: // draw backward, the heading is kept
+		t.Back()
+	case '[': // push current location and direction onto stack
+		t.Push()
+	case ']': // pop last location and direction from stack
+		t.Pop()
+	}
 }
 
 func LsysByName(name string) (LFractal, error) {
@@ -86,35 +250,130 @@ func LsysByName(name string) (LFractal, error) {
 	return LFractal{}, errors.New("No fractal by name: " + name)
 }
 
-func RenderLsys(t io.Writer, fractal LFractal, color color.RGBA, rect image.Rectangle, vector bool) error {
+// PickAxiom returns Axiom, or if Axioms is set one of them chosen by weight using rng
+func (f LFractal) PickAxiom(rng *rand.Rand) (string, error) {
+	if len(f.Axioms) == 0 {
+		return f.Axiom, nil
+	}
+	err := f.validateWeights()
+	if err != nil {
+		return "", err
+	}
+	if len(f.Axioms) == 1 {
+		return f.Axioms[0].Axiom, nil
+	}
+	total := 0.0
+	for _, a := range f.Axioms {
+		total += a.Weight
+	}
+	r := rng.Float64() * total
+	for _, a := range f.Axioms {
+		if r < a.Weight {
+			return a.Axiom, nil
+		}
+		r -= a.Weight
+	}
+	return f.Axioms[len(f.Axioms)-1].Axiom, nil // r rounded up to total
+}
+
+// seededAxiom is PickAxiom with an rng seeded from Seed, the axiom BuildLsys uses
+func (f LFractal) seededAxiom() (string, error) {
+	return f.PickAxiom(rand.New(rand.NewSource(f.Seed)))
+}
+
+// validateWeights checks Axioms weights are finite, not negative, and not all zero
+func (f LFractal) validateWeights() error {
+	total := 0.0
+	for _, a := range f.Axioms {
+		if a.Weight < 0 || math.IsNaN(a.Weight) || math.IsInf(a.Weight, 0) {
+			return fmt.Errorf("%s: invalid weight %v for axiom %q", f.Name, a.Weight, a.Axiom)
+		}
+		total += a.Weight
+	}
+	if len(f.Axioms) > 0 && total == 0 {
+		return fmt.Errorf("%s: axiom weights are all zero", f.Name)
+	}
+	return nil
+}
+
+// BuildLsys generates the fractal string, from an axiom picked with Seed, and draws it in color, returning the unscaled drawing
+func BuildLsys(fractal LFractal, color color.RGBA) (*drawing.Drawing, error) {
 	var drw drawing.Drawing
-	s, err := LSys(fractal.Axiom, fractal.Rules, fractal.Levels)
+	axiom, err := fractal.seededAxiom()
+	if err != nil {
+		return nil, err
+	}
+	s, err := LSysConfig(axiom, fractal.Rules, fractal.Levels, fractal.Turtle)
+	if err != nil {
+		return nil, err
+	}
+	DrawLSysConfig(&drw, s, fractal.Theta, fractal.Angle, color, fractal.OnePath, fractal.Turtle)
+	return &drw, nil
+}
+
+// LsysGeometry returns the unscaled black drawing of the named fractal, for comparing geometry in tests
+func LsysGeometry(name string) (*drawing.Drawing, error) {
+	fractal, err := LsysByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return BuildLsys(fractal, drawing.ColorBLACK)
+}
+
+// ImageDir is the directory RenderLsys and RenderAllLsys write to
+const ImageDir = "images"
+
+// RenderLsys renders fractal to ImageDir/<Name>.svg if vector, else ImageDir/<Name>.png, writing the result line to t
+func RenderLsys(t io.Writer, fractal LFractal, color color.RGBA, rect image.Rectangle, vector bool) error {
+	return RenderLsysTo(t, ImageDir, fractal, color, rect, vector)
+}
+
+// RenderLsysTo is RenderLsys writing into dir, which is created if missing
+func RenderLsysTo(t io.Writer, dir string, fractal LFractal, color color.RGBA, rect image.Rectangle, vector bool) error {
+	drw, err := BuildLsys(fractal, color)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(dir, 0755)
 	if err != nil {
 		return err
 	}
-	DrawLSys(&drw, s, fractal.Theta, fractal.Angle, color, fractal.OnePath)
 	var str string
 
 	if vector {
-		str, err = drw.RenderSvg(&rect, "images/"+fractal.Name+".svg")
+		str, err = drw.RenderSvg(&rect, filepath.Join(dir, fractal.Name+".svg"))
 	} else {
-		str, err = drw.RenderPng(&rect, "images/"+fractal.Name+".png")
+		str, err = drw.RenderPng(&rect, filepath.Join(dir, fractal.Name+".png"))
 	}
 	fmt.Fprintln(t, str)
 	return err
 }
 
+// RenderAllLsys renders every fractal in black at 1024x1024 to ImageDir as svg and png, writing a description and result line per fractal to t
 func RenderAllLsys(t io.Writer) error {
+	return RenderAllLsysTo(t, ImageDir, image.Point{X: 1024, Y: 1024}, drawing.ColorBLACK, []string{"svg", "png"})
+}
+
+// RenderAllLsysTo renders every fractal in color at size to dir/<Name>.<format> for each format (png, jpg, jpeg, svg, tif, tiff),
+// writing a description and result line per fractal to t. dir is created if missing, rendering stops at the first error
+func RenderAllLsysTo(t io.Writer, dir string, size image.Point, color color.RGBA, formats []string) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	r := image.Rectangle{Max: size}
 	for _, f := range fractals {
 		fmt.Fprintln(t, strings.TrimRight(strings.Replace(fmt.Sprintf("== %s == Angle: %v, Axiom: %v, Rules: %v", f.Name, f.Angle, f.Axiom, f.Rules), "map[", "", 1), "]"))
-		var r = image.Rectangle{Min: image.Point{X: 0, Y: 0}, Max: image.Point{X: 1024, Y: 1024}}
-		err := RenderLsys(t, f, drawing.ColorBLACK, r, true)
+		drw, err := BuildLsys(f, color)
 		if err != nil {
 			return err
 		}
-		err = RenderLsys(t, f, drawing.ColorBLACK, r, false)
-		if err != nil {
-			return err
+		for _, format := range formats {
+			str, err := drw.Clone().Render(&r, filepath.Join(dir, f.Name+"."+strings.ToLower(format)))
+			fmt.Fprintln(t, str)
+			if err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -0,0 +1,336 @@
+package lsys
+
+import (
+	"flag"
+	"image"
+	"image/png"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/exyzzy/lsys/drawing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden geometry files in testdata")
+
+// goldenTolerance allows for floating point differences across platforms
+const goldenTolerance = 1e-9
+
+func TestGoldenGeometry(t *testing.T) {
+	for _, name := range []string{"Koch"} {
+		drw, err := LsysGeometry(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		golden := filepath.Join("testdata", name+".json")
+		if *update {
+			f, err := os.Create(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = drw.WriteJSON(f)
+			f.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		f, err := os.Open(golden)
+		if err != nil {
+			t.Fatalf("%v (run go test -update to create it)", err)
+		}
+		want, err := drawing.ReadJSON(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !drw.Equal(want, goldenTolerance) {
+			t.Errorf("%s: geometry differs from %s", name, golden)
+		}
+	}
+}
+
+func TestMoveLength(t *testing.T) {
+	cfg := DefaultTurtleConfig()
+	cfg.MoveLength = 3
+	var drw drawing.Drawing
+	DrawLSysConfig(&drw, "FfF", 0, 90, drawing.ColorBLACK, false, &cfg)
+	if len(drw.Paths) != 2 {
+		t.Fatalf("got %d paths, want 2", len(drw.Paths))
+	}
+	start := drw.Paths[1].Points[0]
+	if math.Abs(start.X-4) > 1e-9 || math.Abs(start.Y) > 1e-9 {
+		t.Errorf("second path starts at %v, want {4 0}", start)
+	}
+}
+
+func TestZeroLengths(t *testing.T) {
+	cfg := DefaultTurtleConfig()
+	cfg.DrawLength, cfg.MoveLength = 0, 0
+	var zero, def drawing.Drawing
+	DrawLSysConfig(&zero, "FfF", 0, 90, drawing.ColorBLACK, false, &cfg)
+	DrawLSys(&def, "FfF", 0, 90, drawing.ColorBLACK, false)
+	if !zero.Equal(&def, 1e-9) {
+		t.Errorf("0 lengths drew %v, want the default %v", zero.Paths, def.Paths)
+	}
+}
+
+func TestNoLeadingEmptyPath(t *testing.T) {
+	for _, s := range []string{"+F", "[F]F", "-[+F]", "ffF", "[]F"} {
+		var drw drawing.Drawing
+		DrawLSys(&drw, s, 0, 90, drawing.ColorBLACK, false)
+		if len(drw.Paths) == 0 || len(drw.Paths[0].Points) < 2 {
+			t.Errorf("%q: leading path %v, want at least 2 points", s, drw.Paths)
+		}
+	}
+	var drw drawing.Drawing
+	DrawLSys(&drw, "+-[]f", 0, 90, drawing.ColorBLACK, false)
+	if len(drw.Paths) != 0 {
+		t.Errorf("no draw commands gave %d paths, want 0", len(drw.Paths))
+	}
+}
+
+func TestLevelZero(t *testing.T) {
+	dir := t.TempDir()
+	rect := image.Rect(0, 0, 64, 64)
+	for _, name := range []string{"Koch", "Hilbert", "Penrose"} { // Hilbert and Penrose axioms draw nothing
+		fractal, _ := LsysByName(name)
+		fractal.Levels = 0
+		s, err := LSys(fractal.Axiom, fractal.Rules, fractal.Levels)
+		if err != nil || s != fractal.Axiom {
+			t.Errorf("%s: level 0 gave %q, %v, want the axiom", name, s, err)
+		}
+		for _, ext := range []string{".png", ".svg"} {
+			drw, err := BuildLsys(fractal, drawing.ColorBLACK)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = drw.Render(&rect, filepath.Join(dir, name+ext))
+			if err != nil {
+				t.Errorf("%s%s: %v", name, ext, err)
+			}
+		}
+	}
+}
+
+func TestNegativeLevel(t *testing.T) {
+	fractal, _ := LsysByName("Koch")
+	fractal.Levels = -1
+	if _, err := BuildLsys(fractal, drawing.ColorBLACK); err == nil {
+		t.Error("expected an error for level -1")
+	}
+}
+
+func TestPenUpStart(t *testing.T) {
+	cfg := DefaultTurtleConfig()
+	cfg.PenUp = true
+	var drw drawing.Drawing
+	DrawLSysConfig(&drw, "FF+F", 0, 90, drawing.ColorBLACK, false, &cfg)
+	if len(drw.Paths) != 0 {
+		t.Errorf("pen up turtle drew %v", drw.Paths)
+	}
+}
+
+func TestRadians(t *testing.T) {
+	cfg := DefaultTurtleConfig()
+	cfg.AngleUnit = Radians
+	var rad, deg drawing.Drawing
+	DrawLSysConfig(&rad, "F+F-F", math.Pi/2, math.Pi/3, drawing.ColorBLACK, false, &cfg)
+	DrawLSys(&deg, "F+F-F", 90, 60, drawing.ColorBLACK, false)
+	if !rad.Equal(&deg, 1e-9) {
+		t.Errorf("radians %v differ from degrees %v", rad.Paths, deg.Paths)
+	}
+}
+
+func TestRenderLsysTo(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "fresh", "images")
+	fractal, _ := LsysByName("Koch")
+	fractal.Levels = 2
+	rect := image.Rect(0, 0, 64, 64)
+	for _, vector := range []bool{true, false} {
+		err := RenderLsysTo(io.Discard, dir, fractal, drawing.ColorBLACK, rect, vector)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"Koch.svg", "Koch.png"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestPenUpDown(t *testing.T) {
+	if _, err := LSys("FUF", map[string]string{"F": "F"}, 1); err == nil {
+		t.Error("pen runes are on by default, U needs no rule")
+	}
+	cfg := DefaultTurtleConfig()
+	cfg.PenUpRune, cfg.PenDownRune = 'U', 'D'
+	s, err := LSysConfig("FUFD", map[string]string{"F": "FF"}, 1, &cfg)
+	if err != nil || s != "FFUFFD" {
+		t.Fatalf("LSys = %q, %v, want FFUFFD", s, err)
+	}
+	var drw drawing.Drawing
+	DrawLSysConfig(&drw, "FUFDF", 0, 90, drawing.ColorBLACK, false, &cfg)
+	if len(drw.Paths) != 2 || len(drw.Paths[0].Points) != 2 || drw.Paths[1].Points[0] != (drawing.FPoint{X: 2, Y: 0}) {
+		t.Errorf("got paths %v, want a gap from 1 to 2", drw.Paths)
+	}
+	cfg.PenUpRune, cfg.PenDownRune = '^', 'v'
+	if _, err := LSysConfig("F^Fv", map[string]string{"F": "F"}, 1, &cfg); err != nil {
+		t.Error(err)
+	}
+	if _, err := LSysConfig("FUF", map[string]string{"F": "F"}, 1, &cfg); err == nil {
+		t.Error("expected no rule error for U when remapped")
+	}
+}
+
+func TestWeightedAxioms(t *testing.T) {
+	f := LFractal{Name: "w", Axioms: []WeightedAxiom{{"F", 1}, {"F+F", 3}, {"FFF", 0}}, Rules: map[string]string{"F": "F"}}
+	counts := map[string]int{}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 4000; i++ {
+		a, err := f.PickAxiom(rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[a]++
+	}
+	if counts["FFF"] != 0 || counts["F+F"] < 2700 || counts["F+F"] > 3300 {
+		t.Errorf("picks %v, want about 1000 F and 3000 F+F", counts)
+	}
+	a, _ := BuildLsys(f, drawing.ColorBLACK)
+	b, _ := BuildLsys(f, drawing.ColorBLACK)
+	if !a.Equal(b, 0) {
+		t.Error("same Seed built different drawings")
+	}
+	single := LFractal{Name: "one", Axioms: []WeightedAxiom{{"FF", 0.5}}}
+	if a, err := single.PickAxiom(nil); err != nil || a != "FF" {
+		t.Errorf("single axiom gave %q, %v", a, err)
+	}
+	for _, bad := range [][]WeightedAxiom{{{"F", -1}, {"F", 2}}, {{"F", 0}, {"FF", 0}}} {
+		f.Axioms = bad
+		if _, err := f.Validate(); err == nil {
+			t.Errorf("expected weight error for %v", bad)
+		}
+		if _, err := BuildLsys(f, drawing.ColorBLACK); err == nil {
+			t.Errorf("expected build error for %v", bad)
+		}
+	}
+}
+
+func TestSimplifyAdaptiveKoch(t *testing.T) {
+	drw, err := LsysGeometry("Koch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := drw.Clone()
+	const baseTol = 2.0
+	drw.SimplifyAdaptive(baseTol)
+	before, after := orig.Stats().Points, drw.Stats().Points
+	if after >= before {
+		t.Fatalf("SimplifyAdaptive kept %d of %d points", after, before)
+	}
+	// no point is ever farther than the straight run tolerance from the simplified curve
+	for i, p := range orig.Paths {
+		for _, pt := range p.Points {
+			if !drw.Paths[i].ContainsPoint(pt, 2*baseTol) {
+				t.Fatalf("point %v is farther than %v from the simplified path", pt, 2*baseTol)
+			}
+		}
+	}
+	t.Logf("Koch: %d points simplified to %d", before, after)
+}
+
+func TestRenderAllLsysTo(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	err := RenderAllLsysTo(io.Discard, dir, image.Point{X: 32, Y: 32}, drawing.ColorGREEN, []string{"PNG"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(files) != len(fractals) {
+		t.Fatalf("%d files, want one png per fractal (%d)", len(files), len(fractals))
+	}
+	f, err := os.Open(filepath.Join(dir, "Koch.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Size() != (image.Point{X: 32, Y: 32}) {
+		t.Errorf("Koch.png is %v", img.Bounds())
+	}
+	green := false
+	for y := 0; y < 32 && !green; y++ {
+		for x := 0; x < 32; x++ {
+			if r, g, b, _ := img.At(x, y).RGBA(); r == 0 && g == 0xffff && b == 0 {
+				green = true
+			}
+		}
+	}
+	if !green {
+		t.Error("Koch.png has no green pixel")
+	}
+}
+
+func TestWillRender(t *testing.T) {
+	cases := map[string]bool{
+		"":       false,
+		"+-[X]f": false,
+		"X+F":    true,
+		"UF":     false,
+		"UFDF":   true,
+		"[U]F":   true,
+		"U[D]F":  false,
+		"U[DF]":  true,
+		"]]UF":   false,
+	}
+	cfg := DefaultTurtleConfig()
+	cfg.PenUpRune, cfg.PenDownRune = 'U', 'D'
+	for s, want := range cases {
+		if got := WillRenderConfig(s, &cfg); got != want {
+			t.Errorf("WillRender(%q) = %v, want %v", s, got, want)
+		}
+	}
+	if !WillRender("UF") {
+		t.Error("WillRender(\"UF\") = false with the default pen runes off")
+	}
+	cfg = DefaultTurtleConfig()
+	cfg.DrawSymbols = "AB"
+	if WillRenderConfig("F", &cfg) || !WillRenderConfig("+B", &cfg) {
+		t.Error("WillRenderConfig ignores DrawSymbols")
+	}
+	var drw drawing.Drawing
+	DrawLSysConfig(&drw, "AFB", 0, 90, drawing.ColorBLACK, true, &cfg)
+	if n := drw.Stats().Points; n != 3 {
+		t.Errorf("AFB with draw symbols AB drew %d points, want 3", n)
+	}
+}
+
+func TestLSysStaged(t *testing.T) {
+	koch, _ := LsysByName("Koch")
+	want, _ := LSys(koch.Axiom, koch.Rules, 3)
+	got, err := LSysStaged(koch.Axiom, []map[string]string{koch.Rules, koch.Rules, koch.Rules})
+	if err != nil || got != want {
+		t.Errorf("same rules every stage differs from LSys: %v", err)
+	}
+	// grow with X, then decompose X into drawing in a final pass
+	grow := map[string]string{"X": "X+X", "F": "F"}
+	decompose := map[string]string{"X": "F[-F]", "F": "FF"}
+	got, err = LSysStaged("X", []map[string]string{grow, grow, decompose})
+	if want := "F[-F]+F[-F]+F[-F]+F[-F]"; err != nil || got != want {
+		t.Errorf("LSysStaged = %q, %v, want %q", got, err, want)
+	}
+	if got, err := LSysStaged("FX", nil); err != nil || got != "FX" {
+		t.Errorf("no stages = %q, %v, want the axiom", got, err)
+	}
+	_, err = LSysStaged("X", []map[string]string{grow, {"F": "F"}})
+	if err == nil || err.Error() != "stage 1: no rule for: X" {
+		t.Errorf("missing rule error %v", err)
+	}
+}
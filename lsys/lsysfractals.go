@@ -3,11 +3,27 @@ package lsys
 type LFractal struct {
 	Name    string            // identifier
 	Axiom   string            // beginning string
+	Axioms  []WeightedAxiom   // candidate beginning strings, one picked per build by weight, replaces Axiom when not empty
+	Seed    int64             // seeds the pick from Axioms
 	Rules   map[string]string // rewrite rules
 	Levels  int               // rewrite generations
 	Theta   float64           // starting angle (orientation)
 	Angle   float64           // turn angle
 	OnePath bool              // force drawing the entire fractal in a single path
+	Turtle  *TurtleConfig     // turtle settings, nil for DefaultTurtleConfig
+}
+
+// WeightedAxiom is a candidate axiom, picked with probability Weight / sum of all weights
+type WeightedAxiom struct {
+	Axiom  string
+	Weight float64
+}
+
+// joinedOnePath keeps the lines OnePath draws across pops, part of the look of fractals designed before OnePath broke at moves
+func joinedOnePath() *TurtleConfig {
+	cfg := DefaultTurtleConfig()
+	cfg.OnePathJoined = true
+	return &cfg
 }
 
 var fractals = []LFractal{
@@ -280,6 +296,7 @@ var fractals = []LFractal{
 		Theta:   0.0,
 		Angle:   15.0,
 		OnePath: true,
+		Turtle:  joinedOnePath(),
 	},
 	{
 		Name:    "Corner",
@@ -0,0 +1,98 @@
+package lsys
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseLsysBlock parses the key=value text block many fractal collections publish, one setting per line:
+//
+//	name=Koch
+//	angle=60
+//	axiom=F
+//	F=F+F--F+F
+//	level=4
+//
+// Keys are name, angle, axiom, level (or levels), theta and onepath, in any case, any single symbol key is a rule.
+// Blank lines and lines starting with # or ; are skipped, spaces around keys and values are trimmed.
+// Blocks often leave out the F and f rules, so F->F and f->f are added when F or f is used without a rule.
+// An axiom is required and the block must parse into a fractal that passes Validate
+func ParseLsysBlock(s string) (LFractal, error) {
+	f := LFractal{Rules: map[string]string{}}
+	seen := make(map[string]bool)
+	for i, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return LFractal{}, fmt.Errorf("line %d: %q is not key=value", i+1, line)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if key == "" {
+			return LFractal{}, fmt.Errorf("line %d: missing key before =", i+1)
+		}
+		if utf8.RuneCountInString(key) == 1 && isVariable([]rune(key)[0]) {
+			if _, dup := f.Rules[key]; dup {
+				return LFractal{}, fmt.Errorf("line %d: second rule for %s", i+1, key)
+			}
+			f.Rules[key] = value
+			continue
+		}
+		name := strings.ToLower(key)
+		if name == "levels" {
+			name = "level"
+		}
+		if seen[name] {
+			return LFractal{}, fmt.Errorf("line %d: %s set twice", i+1, key)
+		}
+		seen[name] = true
+		var err error
+		switch name {
+		case "name":
+			f.Name = value
+		case "axiom":
+			f.Axiom = value
+		case "angle":
+			f.Angle, err = strconv.ParseFloat(value, 64)
+		case "theta":
+			f.Theta, err = strconv.ParseFloat(value, 64)
+		case "level":
+			f.Levels, err = strconv.Atoi(value)
+		case "onepath":
+			f.OnePath, err = strconv.ParseBool(value)
+		default:
+			return LFractal{}, fmt.Errorf("line %d: unknown key %s", i+1, key)
+		}
+		if err != nil {
+			return LFractal{}, fmt.Errorf("line %d: %s: invalid value %q", i+1, key, value)
+		}
+	}
+	if !seen["axiom"] || f.Axiom == "" {
+		if f.Name == "" {
+			return LFractal{}, errors.New("no axiom")
+		}
+		return LFractal{}, fmt.Errorf("%s: no axiom", f.Name)
+	}
+	for _, sym := range []string{"F", "f"} {
+		if _, ok := f.Rules[sym]; ok {
+			continue
+		}
+		used := strings.Contains(f.Axiom, sym)
+		for _, r := range f.Rules {
+			used = used || strings.Contains(r, sym)
+		}
+		if used {
+			f.Rules[sym] = sym
+		}
+	}
+	_, err := f.Validate()
+	if err != nil {
+		return LFractal{}, err
+	}
+	return f, nil
+}
@@ -0,0 +1,63 @@
+package lsys
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLsysBlock(t *testing.T) {
+	f, err := ParseLsysBlock(`
+# the Koch curve
+name = Koch
+Angle=60
+axiom=F++F++F
+F=F-F++F-F
+levels=4
+theta=0
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	koch, _ := LsysByName("Koch")
+	if f.Name != koch.Name || f.Axiom != koch.Axiom || f.Angle != koch.Angle || f.Levels != koch.Levels || f.Rules["F"] != koch.Rules["F"] || len(f.Rules) != 1 {
+		t.Errorf("parsed %+v, want %+v", f, koch)
+	}
+
+	f, err = ParseLsysBlock("axiom=X\nX=F-[[X]+X]+F[+FX]-X\nF=FF\nangle=22.5\nlevel=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Rules) != 2 || f.Rules["X"] != "F-[[X]+X]+F[+FX]-X" || f.Angle != 22.5 {
+		t.Errorf("parsed %+v", f)
+	}
+
+	f, err = ParseLsysBlock("axiom=FX\nX=X+YF+\nY=-FX-Y\nangle=90") // the published dragon, with no F rule
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Rules) != 3 || f.Rules["F"] != "F" {
+		t.Errorf("parsed rules %v, want F->F added", f.Rules)
+	}
+
+	bad := map[string]string{
+		"angle=60\nF=F+F":         "no axiom",
+		"axiom=F\nF=F\nangle":     "line 3",
+		"axiom=F\nF=F\nF=FF":      "second rule for F",
+		"axiom=F\nF=F\nangle=abc": "invalid value",
+		"axiom=F\naxiom=F":        "set twice",
+		"axiom=F\ncolor=red":      "unknown key",
+		"axiom=FX\nF=F":           "no rule for: X",
+		"axiom=F\nF=F\nlevel=-1":  "negative levels",
+	}
+	for block, want := range bad {
+		_, err := ParseLsysBlock(block)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseLsysBlock(%q) error %v, want %q", block, err, want)
+		}
+	}
+	for block, want := range map[string]string{"angle=60": "no axiom", "name=Snow\nangle=60": "Snow: no axiom"} {
+		if _, err := ParseLsysBlock(block); err == nil || err.Error() != want {
+			t.Errorf("ParseLsysBlock(%q) error %v, want %q", block, err, want)
+		}
+	}
+}
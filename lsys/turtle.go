@@ -0,0 +1,221 @@
+package lsys
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/exyzzy/lsys/drawing"
+)
+
+// StackItem is the turtle state saved by Push and restored by Pop
+type StackItem struct {
+	Point      drawing.FPoint
+	Theta      float64
+	Color      color.RGBA
+	Width      float64
+	PenDown    bool
+	DrawLength float64
+	MoveLength float64
+	Bias       float64
+}
+
+// Turtle draws into its Drawing with turtle graphics commands, so any command source can drive it.
+// Angles are in degrees, see drawing.PointFromTheta. A Turtle is not safe for concurrent use, see DrawLSysStream for streaming from other goroutines
+type Turtle struct {
+	*drawing.Drawing
+	Point      drawing.FPoint // current position
+	Theta      float64        // current heading in degrees
+	Color      color.RGBA     // color of new paths, see SetColor
+	Width      float64        // width of new paths, see SetWidth
+	DrawLength float64        // distance moved by Forward
+	MoveLength float64        // distance moved by Move
+	Bias       float64        // turn bias of the current branch, see TurtleConfig.BranchJitter, DrawLSys adds it to the size of each turn
+	OnePath    bool           // keep drawing in one path across moves and pops, see TurtleConfig.OnePathJoined
+	penDown    bool
+	started    bool // a path has been started, the first starts at the first draw so leading turns and moves leave no stray point
+	stack      []StackItem
+	turnSteps  int     // TurtleConfig.TurnSteps
+	breaks     bool    // !TurtleConfig.OnePathJoined
+	jitter     float64 // TurtleConfig.BranchJitter
+	rng        *rand.Rand
+	drawn      bool    // the last command drew into Point, so a turn here is a corner that can be rounded
+	drawTheta  float64 // direction of the last draw
+}
+
+// NewTurtle returns a turtle at the origin heading theta, drawing into drw in color, with settings from cfg, nil for DefaultTurtleConfig.
+// cfg.AngleUnit is not used, the turtle always works in degrees
+func NewTurtle(drw *drawing.Drawing, theta float64, color color.RGBA, onePath bool, cfg *TurtleConfig) *Turtle {
+	if cfg == nil {
+		def := DefaultTurtleConfig()
+		cfg = &def
+	}
+	drawLength, moveLength := cfg.lengths()
+	t := &Turtle{Drawing: drw, Theta: theta, Color: color, DrawLength: drawLength, MoveLength: moveLength, OnePath: onePath, penDown: !cfg.PenUp, turnSteps: cfg.TurnSteps, breaks: !cfg.OnePathJoined, jitter: cfg.BranchJitter}
+	if t.jitter > 0 {
+		t.rng = rand.New(rand.NewSource(cfg.BranchSeed))
+	}
+	return t
+}
+
+// Forward moves DrawLength along the heading, drawing if the pen is down
+func (t *Turtle) Forward() {
+	t.draw(t.Theta)
+}
+
+// Back moves DrawLength against the heading without turning, drawing if the pen is down
+func (t *Turtle) Back() {
+	t.draw(t.Theta + 180)
+}
+
+// draw moves DrawLength toward heading, drawing if the pen is down
+func (t *Turtle) draw(heading float64) {
+	if !t.penDown {
+		t.Point = drawing.PointFromTheta(t.Point, heading, t.DrawLength)
+		t.drawn = false
+		t.jump()
+		return
+	}
+	if !t.started {
+		t.newPath()
+		t.started = true
+	}
+	if t.turnSteps > 0 && t.drawn && math.Mod(heading-t.drawTheta, 360) != 0 {
+		t.roundCorner(heading)
+	}
+	t.Point = drawing.PointFromTheta(t.Point, heading, t.DrawLength)
+	t.LineTo(t.Point)
+	t.drawn, t.drawTheta = true, heading
+}
+
+// roundCorner replaces the corner at Point, the last point drawn, with a curve from half way back along the last draw to half way along the next, toward heading, see TurtleConfig.TurnSteps
+func (t *Turtle) roundCorner(heading float64) {
+	pa := &t.Paths[len(t.Paths)-1]
+	pa.Points = pa.Points[:len(pa.Points)-1]
+	c := t.Point
+	p0 := drawing.PointFromTheta(c, t.drawTheta, -t.DrawLength/2)
+	p2 := drawing.PointFromTheta(c, heading, t.DrawLength/2)
+	for i := 0; i <= t.turnSteps; i++ {
+		u := float64(i) / float64(t.turnSteps)
+		a, b, d := (1-u)*(1-u), 2*(1-u)*u, u*u
+		pa.Points = append(pa.Points, drawing.FPoint{X: a*p0.X + b*c.X + d*p2.X, Y: a*p0.Y + b*c.Y + d*p2.Y})
+	}
+}
+
+// Move moves MoveLength along the heading without drawing
+func (t *Turtle) Move() {
+	t.move(t.MoveLength)
+}
+
+// move moves length along the heading without drawing, starting a new path unless OnePath
+func (t *Turtle) move(length float64) {
+	t.Point = drawing.PointFromTheta(t.Point, t.Theta, length)
+	t.drawn = false
+	t.jump()
+}
+
+// jump starts a new path at the current point after a move without drawing, unless nothing is drawn yet.
+// With OnePath it only does if the move left the end of the path, and never with OnePathJoined
+func (t *Turtle) jump() {
+	if !t.started {
+		return
+	}
+	if !t.OnePath {
+		t.newPath()
+		return
+	}
+	pts := t.Paths[len(t.Paths)-1].Points
+	switch {
+	case !t.breaks || pts[len(pts)-1] == t.Point:
+	case len(pts) == 1: // nothing drawn since the last break, move its start rather than leave a stray point
+		pts[0] = t.Point
+	default:
+		t.newPath()
+	}
+}
+
+// newPath starts a path at the current point with the current color and width
+func (t *Turtle) newPath() {
+	t.MoveTo(t.Point, t.Color)
+	t.Paths[len(t.Paths)-1].Width = t.Width
+}
+
+// restyle starts a new path after a color or width change, even with OnePath, as a path has one color and width
+func (t *Turtle) restyle() {
+	if !t.started {
+		return
+	}
+	last := &t.Paths[len(t.Paths)-1]
+	if len(last.Points) < 2 { // nothing drawn in it yet, restyle in place
+		last.Color, last.Width = t.Color, t.Width
+	} else if last.Color != t.Color || last.Width != t.Width {
+		t.newPath()
+	}
+}
+
+// SetColor sets the color of the paths drawn from here on
+func (t *Turtle) SetColor(c color.RGBA) {
+	t.Color = c
+	t.restyle()
+}
+
+// SetWidth sets the width of the paths drawn from here on
+func (t *Turtle) SetWidth(w float64) {
+	t.Width = w
+	t.restyle()
+}
+
+// Turn adds angle degrees to the heading
+func (t *Turtle) Turn(angle float64) {
+	t.Theta += angle
+}
+
+// PenUp stops drawing, Forward then moves without drawing
+func (t *Turtle) PenUp() {
+	t.penDown = false
+}
+
+// PenDown starts drawing again
+func (t *Turtle) PenDown() {
+	t.penDown = true
+}
+
+// IsPenDown reports whether Forward draws
+func (t *Turtle) IsPenDown() bool {
+	return t.penDown
+}
+
+// Push saves the whole turtle state: position, heading, color, width, pen, step lengths and bias, then starts a branch with a new Bias if BranchJitter is set
+func (t *Turtle) Push() {
+	t.stack = append(t.stack, StackItem{Point: t.Point, Theta: t.Theta, Color: t.Color, Width: t.Width, PenDown: t.penDown, DrawLength: t.DrawLength, MoveLength: t.MoveLength, Bias: t.Bias})
+	if t.jitter > 0 {
+		t.Bias = (t.rng.Float64()*2 - 1) * t.jitter
+	}
+}
+
+// Pop restores the last pushed state, so changes inside a branch do not leak out of it, returning false and doing nothing if nothing is pushed
+func (t *Turtle) Pop() bool {
+	n := len(t.stack) - 1
+	if n < 0 {
+		return false
+	}
+	se := t.stack[n]
+	t.stack = t.stack[:n]
+	t.Point = se.Point
+	t.Theta = se.Theta
+	t.Color = se.Color
+	t.Width = se.Width
+	t.penDown = se.PenDown
+	t.DrawLength = se.DrawLength
+	t.MoveLength = se.MoveLength
+	t.Bias = se.Bias
+	t.drawn = false
+	t.jump()
+	t.restyle() // OnePath does not jump, but a restored color still needs its own path
+	return true
+}
+
+// Depth returns the number of pushed states
+func (t *Turtle) Depth() int {
+	return len(t.stack)
+}
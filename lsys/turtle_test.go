@@ -0,0 +1,239 @@
+package lsys
+
+import (
+	"math"
+	"testing"
+
+	"github.com/exyzzy/lsys/drawing"
+)
+
+func near(p drawing.FPoint, x, y float64) bool {
+	return math.Abs(p.X-x) < 1e-9 && math.Abs(p.Y-y) < 1e-9
+}
+
+func TestTurtleSteps(t *testing.T) {
+	var drw drawing.Drawing
+	tu := NewTurtle(&drw, 0, drawing.ColorRED, false, nil)
+	tu.Turn(90)
+	tu.Forward()
+	if !near(tu.Point, 0, 1) || len(drw.Paths) != 1 {
+		t.Fatalf("after Forward at 90: %v, %d paths", tu.Point, len(drw.Paths))
+	}
+	tu.Push()
+	tu.Turn(-90)
+	tu.Forward()
+	if tu.Depth() != 1 || !near(tu.Point, 1, 1) {
+		t.Fatalf("after Push, Turn, Forward: %v depth %d", tu.Point, tu.Depth())
+	}
+	if !tu.Pop() || !near(tu.Point, 0, 1) || tu.Theta != 90 || len(drw.Paths) != 2 {
+		t.Fatalf("after Pop: %v %v, %d paths", tu.Point, tu.Theta, len(drw.Paths))
+	}
+	if tu.Pop() {
+		t.Error("Pop on an empty stack returned true")
+	}
+	tu.Move()
+	if !near(tu.Point, 0, 2) || len(drw.Paths) != 3 {
+		t.Errorf("after Move: %v, %d paths", tu.Point, len(drw.Paths))
+	}
+	tu.PenUp()
+	tu.Forward()
+	if tu.IsPenDown() || len(drw.Paths[len(drw.Paths)-1].Points) != 1 {
+		t.Error("Forward drew with the pen up")
+	}
+}
+
+func TestDrawLSysUnbalancedPop(t *testing.T) {
+	var drw drawing.Drawing
+	DrawLSys(&drw, "F]]F", 0, 90, drawing.ColorBLACK, false)
+	if len(drw.Paths) == 0 {
+		t.Error("nothing drawn")
+	}
+}
+
+func TestTurtleBranchStyle(t *testing.T) {
+	var drw drawing.Drawing
+	tu := NewTurtle(&drw, 0, drawing.ColorBLACK, true, nil)
+	tu.Forward()
+	tu.Push()
+	tu.SetColor(drawing.ColorRED)
+	tu.SetWidth(3)
+	tu.PenUp()
+	tu.Forward()
+	tu.PenDown()
+	tu.Forward()
+	tu.Pop()
+	tu.Forward()
+	if tu.Color != drawing.ColorBLACK || tu.Width != 0 || !tu.IsPenDown() {
+		t.Fatalf("state after Pop: %v width %v pen %v", tu.Color, tu.Width, tu.IsPenDown())
+	}
+	last := drw.Paths[len(drw.Paths)-1]
+	if last.Color != drawing.ColorBLACK || last.Width != 0 || !near(last.Points[len(last.Points)-1], 2, 0) {
+		t.Errorf("path after the branch: %v width %v ends %v", last.Color, last.Width, last.Points[len(last.Points)-1])
+	}
+	if red := drw.Paths[1]; red.Color != drawing.ColorRED || red.Width != 3 {
+		t.Errorf("branch path: %v width %v", red.Color, red.Width)
+	}
+}
+
+func TestTurtleTurnSteps(t *testing.T) {
+	cfg := DefaultTurtleConfig()
+	cfg.TurnSteps = 4
+	var drw drawing.Drawing
+	DrawLSysConfig(&drw, "F+F+F+F", 0, 90, drawing.ColorBLACK, false, &cfg)
+	pts := drw.Paths[0].Points
+	if want := 2 + 3*(cfg.TurnSteps+1); len(pts) != want {
+		t.Fatalf("%d points, want %d", len(pts), want)
+	}
+	if !near(pts[1], 0.5, 0) || !near(pts[1+cfg.TurnSteps], 1, 0.5) || !near(pts[len(pts)-1], 0, 0) {
+		t.Errorf("rounded square does not run middle to middle and back to the start: %v", pts)
+	}
+	for _, pt := range pts {
+		if near(pt, 1, 0) || near(pt, 1, 1) || near(pt, 0, 1) {
+			t.Errorf("corner %v kept", pt)
+		}
+	}
+	// the turn into the branch is rounded, drawing on after the pop has no corner
+	drw = drawing.Drawing{}
+	DrawLSysConfig(&drw, "F[+F]F", 0, 90, drawing.ColorBLACK, false, &cfg)
+	if n := drw.Stats().Points; n != 2+cfg.TurnSteps+1+2 {
+		t.Errorf("branching drawing has %d points", n)
+	}
+}
+
+func TestDrawLSysStream(t *testing.T) {
+	koch, _ := LsysByName("Koch")
+	s, err := LSys(koch.Axiom, koch.Rules, koch.Levels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want drawing.Drawing
+	DrawLSys(&want, s, koch.Theta, koch.Angle, drawing.ColorBLACK, koch.OnePath)
+
+	// producers generate in turn, each handing the next its turn, while the consumer draws, run with -race
+	const producers = 4
+	symbols := make(chan rune, 64)
+	turns := make([]chan struct{}, producers+1)
+	for i := range turns {
+		turns[i] = make(chan struct{})
+	}
+	chunk := (len(s) + producers - 1) / producers
+	for p := 0; p < producers; p++ {
+		go func(p int) {
+			<-turns[p]
+			lo, hi := p*chunk, (p+1)*chunk
+			if lo > len(s) {
+				lo = len(s)
+			}
+			if hi > len(s) {
+				hi = len(s)
+			}
+			for _, v := range s[lo:hi] {
+				symbols <- v
+			}
+			close(turns[p+1])
+		}(p)
+	}
+	go func() {
+		<-turns[producers]
+		close(symbols)
+	}()
+	close(turns[0])
+	var got drawing.Drawing
+	DrawLSysStream(&got, symbols, koch.Theta, koch.Angle, drawing.ColorBLACK, koch.OnePath, nil)
+	if !got.Equal(&want, 0) {
+		t.Errorf("streamed drawing differs: %d paths %d points, want %d paths %d points", len(got.Paths), got.Stats().Points, len(want.Paths), want.Stats().Points)
+	}
+}
+
+func TestDrawLSysBack(t *testing.T) {
+	if _, err := LSys("Fb", map[string]string{"F": "F"}, 1); err == nil {
+		t.Error("the back rune is on by default, b needs no rule")
+	}
+	cfg := DefaultTurtleConfig()
+	cfg.BackRune = 'b'
+	s, err := LSysConfig("X", map[string]string{"X": "F+Fb"}, 1, &cfg)
+	if err != nil || s != "F+Fb" {
+		t.Fatalf("LSys = %q, %v, want the back symbol passed through", s, err)
+	}
+	var drw drawing.Drawing
+	DrawLSysConfig(&drw, s+"F", 0, 90, drawing.ColorBLACK, false, &cfg)
+	pts := drw.Paths[0].Points
+	if len(pts) != 5 || !near(pts[2], 1, 1) || !near(pts[3], 1, 0) || !near(pts[4], 1, 1) {
+		t.Errorf("back then forward drew %v", pts)
+	}
+	if WillRender("b") {
+		t.Error("WillRender counts b as drawing with the default back rune off")
+	}
+	cfg.PenUpRune = 'U'
+	if !WillRenderConfig("b", &cfg) || WillRenderConfig("Ub", &cfg) {
+		t.Error("WillRender does not count the back symbol as drawing")
+	}
+}
+
+func TestOnePathJoined(t *testing.T) {
+	s := "F[+F]f-F"
+	var joined, broken drawing.Drawing
+	cfg := DefaultTurtleConfig()
+	DrawLSysConfig(&broken, s, 0, 90, drawing.ColorBLACK, true, &cfg)
+	cfg.OnePathJoined = true
+	DrawLSysConfig(&joined, s, 0, 90, drawing.ColorBLACK, true, &cfg)
+	if len(joined.Paths) != 1 {
+		t.Errorf("without breaks %d paths, want 1", len(joined.Paths))
+	}
+	// the pop back to (1,0) and the f move leave the path, one break as nothing is drawn between them
+	if len(broken.Paths) != 2 || broken.TotalLength() != 3 || !near(broken.Paths[1].Points[0], 2, 0) {
+		t.Errorf("with breaks %v, want 2 paths drawing 3", broken.Paths)
+	}
+	var zero drawing.Drawing
+	DrawLSysConfig(&zero, s, 0, 90, drawing.ColorBLACK, true, &TurtleConfig{})
+	if !zero.Equal(&broken, 1e-9) {
+		t.Errorf("the zero TurtleConfig drew %v, want the breaks %v", zero.Paths, broken.Paths)
+	}
+	// nothing moves inside the branch, the pop stays on the path end
+	var still drawing.Drawing
+	DrawLSys(&still, "F[+-]F", 0, 90, drawing.ColorBLACK, true)
+	if len(still.Paths) != 1 {
+		t.Errorf("a pop to the path end broke the path: %d paths", len(still.Paths))
+	}
+}
+
+func TestBranchJitter(t *testing.T) {
+	tree, _ := LsysByName("Tree1")
+	s, err := LSys(tree.Axiom, tree.Rules, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	draw := func(jitter float64, seed int64) *drawing.Drawing {
+		cfg := DefaultTurtleConfig()
+		cfg.BranchJitter, cfg.BranchSeed = jitter, seed
+		var drw drawing.Drawing
+		DrawLSysConfig(&drw, s, tree.Theta, tree.Angle, drawing.ColorBLACK, tree.OnePath, &cfg)
+		return &drw
+	}
+	var plain drawing.Drawing
+	DrawLSys(&plain, s, tree.Theta, tree.Angle, drawing.ColorBLACK, tree.OnePath)
+	if !draw(0, 7).Equal(&plain, 0) {
+		t.Error("no jitter changed the tree")
+	}
+	a := draw(5, 7)
+	if !a.Equal(draw(5, 7), 0) {
+		t.Error("the same seed drew different trees")
+	}
+	if a.Equal(draw(5, 8), 1e-9) || a.Equal(&plain, 1e-9) {
+		t.Error("the seed or jitter made no difference")
+	}
+
+	// one branch turns by the same biased angle each time, and the trunk after it is unbiased
+	cfg := DefaultTurtleConfig()
+	cfg.BranchJitter, cfg.BranchSeed = 10, 1
+	var drw drawing.Drawing
+	DrawLSysConfig(&drw, "F[+F+F]+F", 0, 30, drawing.ColorBLACK, false, &cfg)
+	branch, trunk := drw.Paths[0], drw.Paths[1]
+	first, second := branch.SegmentAngle(1)-branch.SegmentAngle(0), branch.SegmentAngle(2)-branch.SegmentAngle(1)
+	if math.Abs(first-second) > 1e-9 || math.Abs(first-30) < 1e-9 || math.Abs(first-30) > 10 {
+		t.Errorf("branch turns %v and %v, want the same biased 30", first, second)
+	}
+	if a := trunk.SegmentAngle(0); math.Abs(a-30) > 1e-9 {
+		t.Errorf("trunk turn after the branch %v, want 30", a)
+	}
+}